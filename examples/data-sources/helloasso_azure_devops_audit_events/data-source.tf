@@ -0,0 +1,24 @@
+resource "time_offset" "review_start" {
+  offset_days = -30
+}
+
+# PATs created and revoked during the last 30 days
+data "helloasso_azure_devops_audit_events" "tokens" {
+  azure_devops_organization = "myorganization"
+  auth_method               = "oidc"
+  app_client_id             = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+
+  start_time = time_offset.review_start.rfc3339
+  actions    = ["Token.PatCreateEvent", "Token.PatRevokeEvent"]
+}
+
+output "pat_review" {
+  value = [
+    for event in data.helloasso_azure_devops_audit_events.tokens.events : {
+      when   = event.timestamp
+      who    = event.actor_display_name
+      action = event.action_id
+      pat    = jsondecode(event.data)["DisplayName"]
+    }
+  ]
+}
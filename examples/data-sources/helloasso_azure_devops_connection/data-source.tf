@@ -0,0 +1,13 @@
+data "helloasso_azure_devops_connection" "example" {
+  azure_devops_organization = "myorganization"
+  auth_method               = "oidc"
+  app_client_id             = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+}
+
+# Fails the run early when the credentials authenticate as someone else
+check "gitops_identity" {
+  assert {
+    condition     = data.helloasso_azure_devops_connection.example.display_name == "GitOps"
+    error_message = "Credentials authenticate as ${data.helloasso_azure_devops_connection.example.display_name} in tenant ${data.helloasso_azure_devops_connection.example.tenant_id}"
+  }
+}
@@ -0,0 +1,16 @@
+data "helloasso_azure_devops_pat_policy" "example" {
+  azure_devops_organization = "myorganization"
+  auth_method               = "oidc"
+  app_client_id             = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+}
+
+resource "helloasso_azure_pat" "example" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = "vso.code"
+  azure_devops_organization = "myorganization"
+  auth_method               = "oidc"
+  app_client_id             = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+
+  # The longest validity the organization allows, 0 when it sets no maximum, null when the experimental policy endpoint is not served
+  validity_days = coalesce(data.helloasso_azure_devops_pat_policy.example.max_lifespan_days, 0) > 0 ? min(365, data.helloasso_azure_devops_pat_policy.example.max_lifespan_days) : 365
+}
@@ -0,0 +1,25 @@
+variable "pat_scopes" {
+  type    = list(string)
+  default = ["vso.code_write", "vso.packaging"]
+
+  validation {
+    condition     = alltrue([for scope in var.pat_scopes : contains(data.helloasso_azure_devops_scopes.all.names, scope)])
+    error_message = "Unknown Azure Devops scopes ${join(", ", setsubtract(var.pat_scopes, data.helloasso_azure_devops_scopes.all.names))}"
+  }
+}
+
+data "helloasso_azure_devops_scopes" "all" {}
+
+# Read-only scopes of the code area: vso.code
+data "helloasso_azure_devops_scopes" "code_read" {
+  area  = "code"
+  level = "read"
+}
+
+resource "helloasso_azure_pat" "reader" {
+  pat_name                  = "reader"
+  azure_devops_pat_scopes   = join(" ", data.helloasso_azure_devops_scopes.code_read.names)
+  azure_devops_organization = "myorganization"
+  auth_method               = "oidc"
+  app_client_id             = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+}
@@ -0,0 +1,20 @@
+# Azure Devops token of the service principal, no PAT needed
+ephemeral "helloasso_azure_access_token" "devops" {
+  azure_devops_organization = "myorganization"
+  auth_method               = "oidc"
+  app_client_id             = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+}
+
+provider "restapi" {
+  uri = "https://dev.azure.com/myorganization"
+  headers = {
+    Authorization = "Bearer ${ephemeral.helloasso_azure_access_token.devops.access_token}"
+  }
+}
+
+# Microsoft Graph token, the tenant is given instead of discovered from an organization
+ephemeral "helloasso_azure_access_token" "graph" {
+  authority   = "128c6ba1-f30f-4176-87d4-a93c61ae4ef0"
+  auth_method = "managed_identity"
+  scopes      = ["https://graph.microsoft.com/.default"]
+}
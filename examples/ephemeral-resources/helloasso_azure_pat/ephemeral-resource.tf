@@ -0,0 +1,14 @@
+# The PAT only lives for the duration of the terraform run, it is revoked once done
+ephemeral "helloasso_azure_pat" "example" {
+  pat_name                  = "terraform-run"
+  azure_devops_pat_scopes   = "vso.code vso.packaging"
+  azure_devops_organization = "myorganization"
+  auth_method               = "oidc"
+  app_client_id             = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+  validity_minutes          = 30
+}
+
+provider "azuredevops" {
+  org_service_url       = "https://dev.azure.com/myorganization"
+  personal_access_token = ephemeral.helloasso_azure_pat.example.pat
+}
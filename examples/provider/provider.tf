@@ -1 +1,20 @@
 provider "helloasso" {}
+
+# Tenants living in Azure Government, signing in to the public Azure Devops
+provider "helloasso" {
+  alias       = "usgov"
+  environment = "usgovernment"
+}
+
+# Public keys exported with 'keybase pgp export -q <username> > keys/<username>.asc'
+# for 'pgp_key = "keybase:<username>"'
+provider "helloasso" {
+  alias           = "keybase"
+  pgp_keybase_dir = "${path.module}/keys"
+}
+
+# Fail the plan, rather than the apply, on broken credentials
+provider "helloasso" {
+  alias                      = "verified"
+  verify_credentials_on_plan = true
+}
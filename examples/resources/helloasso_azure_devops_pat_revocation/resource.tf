@@ -0,0 +1,14 @@
+# Revoke every PAT of a user whose laptop was stolen, along with a PAT found in a public repository,
+# as a managed identity added to the 'Project Collection Administrators' group
+resource "helloasso_azure_devops_pat_revocation" "incident_1234" {
+  azure_devops_organization = "myorganization"
+  auth_method               = "managed_identity"
+
+  user_descriptors = ["aad.MGQwYzNlN2EtMmE2MS00ZjliLWEzYTgtNGY2YjBlMWM5ZDEx"]
+  pat_ids          = ["4f1a2b3c-5d6e-4f70-8a9b-0c1d2e3f4a5b"]
+  reason           = "INC-1234 laptop stolen, PAT pushed to a public repository"
+}
+
+output "revoked_pat_ids" {
+  value = helloasso_azure_devops_pat_revocation.incident_1234.revoked_pat_ids
+}
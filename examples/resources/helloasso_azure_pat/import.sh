@@ -0,0 +1,3 @@
+# PATs are imported by <azure_devops_organization>/<pat_id>, the PAT secret can not be read back.
+# The next apply checks the PAT name and scopes match the configuration and records them, without replacing the PAT
+terraform import helloasso_azure_pat.example myorganization/5d8f1c3a-0b4e-4f6d-9a2c-7e1b3d5f9a8c
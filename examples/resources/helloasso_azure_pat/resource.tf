@@ -1,4 +1,8 @@
-
+variable "azure_devops_password" {
+  type      = string
+  sensitive = true
+  ephemeral = true
+}
 
 resource "time_rotating" "rotate_pass" {
   rotation_days = 90
@@ -10,11 +14,79 @@ resource "helloasso_azure_pat" "example" {
   app_client_id             = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
   authority                 = "https://login.microsoftonline.com/128c6ba1-f30f-4176-87d4-a93c61ae4ef0"
   azure_devops_user         = "user@myorganization.com"
-  azure_devops_password     = "usersuperpassword"
   azure_devops_pat_endpoint = "https://vssps.dev.azure.com/myorganization/_apis/tokens/pats"
 
+  # Read at apply time and on destroy, never stored in state, so that rotated PATs are revoked
+  azure_devops_password_command = "pass show azure/devops"
+
   # For now it does not support private App - must be true
   is_app_registration_public = true
 
   rotate_when_changed = time_rotating.rotate_pass.id
 }
+
+# In GitHub Actions (permissions: id-token: write) or Azure Pipelines,
+# exchange the pipeline OIDC token instead of storing a password or a secret
+# PAT endpoint and tenant authority are derived from the organization
+resource "helloasso_azure_pat" "from_ci" {
+  pat_name                  = "gitops-ci"
+  azure_devops_pat_scopes   = "vso.code"
+  app_client_id             = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+  azure_devops_organization = "myorganization"
+
+  auth_method = "oidc"
+
+  # Organizations may cap the PAT lifespan, shorten the PAT to the cap rather than fail
+  validity_days       = 180
+  on_policy_violation = "clamp"
+}
+
+# On a self-hosted runner, use the managed identity of the Azure VM
+resource "helloasso_azure_pat" "from_runner" {
+  pat_name                  = "gitops-runner"
+  azure_devops_pat_scopes   = "vso.code"
+  azure_devops_organization = "myorganization"
+
+  auth_method = "managed_identity"
+  # Only for a user-assigned identity
+  managed_identity_client_id = "0b2e3f9c-4b5a-4a8e-9d6c-2f1e0a7b8c9d"
+}
+
+# Only store the PAT encrypted for a PGP key, decrypt it with
+# terraform output -raw encrypted_pat | gpg --decrypt
+resource "helloasso_azure_pat" "encrypted" {
+  pat_name                  = "gitops-encrypted"
+  azure_devops_pat_scopes   = "vso.code"
+  app_client_id             = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+  azure_devops_organization = "myorganization"
+  azure_devops_user         = "user@myorganization.com"
+
+  # Write-only, the password is never stored in plan nor state (Terraform >= 1.11), bump the version when it changes.
+  # As it is not kept after apply, terraform can not revoke this PAT on destroy, replacement nor rotation,
+  # revoke the PATs left behind with helloasso_azure_pat_reaper and managed_only
+  azure_devops_password_wo         = var.azure_devops_password
+  azure_devops_password_wo_version = 1
+
+  # or a base64 public key, e.g. filebase64("gitops.gpg")
+  pgp_key = "keybase:gitops"
+}
+
+# Read the secret at apply time from a mounted Kubernetes or Docker secret,
+# or from a password manager with 'app_client_secret_command = "op read op://vault/app/secret"'
+resource "helloasso_azure_pat" "from_secret_file" {
+  pat_name                   = "gitops-secret-file"
+  azure_devops_pat_scopes    = "vso.code"
+  app_client_id              = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+  azure_devops_organization  = "myorganization"
+  is_app_registration_public = false
+
+  app_client_secret_file = "/run/secrets/app_client_secret"
+}
+
+# Plans warn 30 days before expiry by default, checks can enforce a stricter margin
+check "gitops_pat_expiry" {
+  assert {
+    condition     = helloasso_azure_pat.example.days_until_expiry > 14
+    error_message = "PAT ${helloasso_azure_pat.example.pat_name} expires on ${helloasso_azure_pat.example.valid_to}, rotate it"
+  }
+}
@@ -0,0 +1,18 @@
+# Revoke the PATs left behind by years of rotations, or by the write-only credentials
+# of helloasso_azure_pat which can not revoke them, except the current one
+resource "helloasso_azure_pat_reaper" "gitops" {
+  azure_devops_organization = "myorganization"
+  app_client_id             = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+  azure_devops_user         = "user@myorganization.com"
+  azure_devops_password     = var.azure_devops_password
+
+  name_prefix  = "gitops"
+  keep_pat_ids = [helloasso_azure_pat.example.pat_id]
+
+  # Only list in revoked_pat_ids what would be revoked, set to false once reviewed
+  dry_run = true
+}
+
+output "stale_pat_ids" {
+  value = helloasso_azure_pat_reaper.gitops.revoked_pat_ids
+}
@@ -0,0 +1,106 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AUDIT_API_VERSION string = "7.1-preview.1"
+	// AUDIT_BATCH_SIZE is the number of events asked per page
+	AUDIT_BATCH_SIZE int = 200
+)
+
+// AuditLogEntry is an event of the organization audit log, ActionID tells what happened, e.g. Token.PatCreateEvent.
+type AuditLogEntry struct {
+	ID                      string          `json:"id"`
+	CorrelationID           string          `json:"correlationId"`
+	ActionID                string          `json:"actionId"`
+	Area                    string          `json:"area"`
+	Category                string          `json:"category"`
+	Timestamp               time.Time       `json:"timestamp"`
+	ActorUserID             string          `json:"actorUserId"`
+	ActorUPN                string          `json:"actorUPN"`
+	ActorDisplayName        string          `json:"actorDisplayName"`
+	IPAddress               string          `json:"ipAddress"`
+	ScopeDisplayName        string          `json:"scopeDisplayName"`
+	ProjectName             string          `json:"projectName"`
+	Details                 string          `json:"details"`
+	Data                    json.RawMessage `json:"data"`
+	AuthenticationMechanism string          `json:"authenticationMechanism"`
+}
+
+type AuditLogQueryResult struct {
+	DecoratedAuditLogEntries []AuditLogEntry `json:"decoratedAuditLogEntries"`
+	ContinuationToken        string          `json:"continuationToken"`
+	HasMore                  bool            `json:"hasMore"`
+}
+
+type AuditLogOptions struct {
+	StartTime time.Time
+	EndTime   time.Time
+	// Actions keeps the events whose action ID matches one of them, a trailing '*' matching any suffix, e.g. 'Token.*'
+	Actions []string
+	// MaxEntries stops the query once this many events are kept, 0 for no limit
+	MaxEntries int
+}
+
+// MatchAuditAction tells whether actionID matches one of actions, a trailing '*' matching any suffix.
+// Every action matches when actions is empty.
+func MatchAuditAction(actionID string, actions []string) bool {
+	if len(actions) == 0 {
+		return true
+	}
+	for _, action := range actions {
+		if prefix, ok := strings.CutSuffix(action, "*"); ok && strings.HasPrefix(actionID, prefix) {
+			return true
+		}
+		if action == actionID {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryAuditLog returns the events of the audit log at endpoint (https://auditservice.dev.azure.com/<organization>/_apis/audit/auditlog)
+// between opts.StartTime and opts.EndTime matching opts.Actions, following continuation tokens.
+func QueryAuditLog(ctx context.Context, httpClient *http.Client, endpoint string, tokens TokenProvider, scope string, opts AuditLogOptions) ([]AuditLogEntry, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	query := url.Values{}
+	query.Set("api-version", AUDIT_API_VERSION)
+	query.Set("startTime", opts.StartTime.UTC().Format(time.RFC3339Nano))
+	if !opts.EndTime.IsZero() {
+		query.Set("endTime", opts.EndTime.UTC().Format(time.RFC3339Nano))
+	}
+	query.Set("batchSize", strconv.Itoa(AUDIT_BATCH_SIZE))
+	// Aggregated entries merge similar events, compliance reviews need each of them
+	query.Set("skipAggregation", "true")
+
+	entries := []AuditLogEntry{}
+	for {
+		page := &AuditLogQueryResult{}
+		if err := doAPI(ctx, httpClient, tokens, scope, http.MethodGet, endpoint+"?"+query.Encode(), nil, page); err != nil {
+			return nil, err
+		}
+		for _, entry := range page.DecoratedAuditLogEntries {
+			if !MatchAuditAction(entry.ActionID, opts.Actions) {
+				continue
+			}
+			entries = append(entries, entry)
+			if opts.MaxEntries > 0 && len(entries) >= opts.MaxEntries {
+				return entries, nil
+			}
+		}
+		if !page.HasMore || page.ContinuationToken == "" {
+			return entries, nil
+		}
+		query.Set("continuationToken", page.ContinuationToken)
+	}
+}
@@ -0,0 +1,24 @@
+package azdo
+
+import "testing"
+
+func TestMatchAuditAction(t *testing.T) {
+	for _, test := range []struct {
+		actionID string
+		actions  []string
+		match    bool
+	}{
+		{"Token.PatCreateEvent", nil, true},
+		{"Token.PatCreateEvent", []string{"Token.*"}, true},
+		{"Token.PatCreateEvent", []string{"Token.PatCreateEvent"}, true},
+		{"Token.PatCreateEvent", []string{"Token.PatRevokeEvent", "Token.Pat*"}, true},
+		{"Token.PatCreateEvent", []string{"Token.PatRevokeEvent"}, false},
+		{"Git.RepositoryCreated", []string{"Token.*"}, false},
+		{"Token.PatCreateEvent", []string{"Token"}, false},
+		{"Git.RepositoryCreated", []string{"*"}, true},
+	} {
+		if match := MatchAuditAction(test.actionID, test.actions); match != test.match {
+			t.Errorf("MatchAuditAction(%q, %v) = %v", test.actionID, test.actions, match)
+		}
+	}
+}
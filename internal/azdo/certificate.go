@@ -0,0 +1,96 @@
+package azdo
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/confidential"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// readCertificateSource returns the raw certificate content, given either inline
+// (PEM, or base64 encoded PFX) or as a path to a PEM or PFX file.
+func readCertificateSource(certificate string) ([]byte, error) {
+	if strings.Contains(certificate, "-----BEGIN") {
+		return []byte(certificate), nil
+	}
+	if _, err := os.Stat(certificate); err == nil {
+		return os.ReadFile(certificate)
+	}
+	content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(certificate))
+	if err != nil {
+		return nil, fmt.Errorf("certificate is neither inline PEM, a readable file nor base64 encoded PFX")
+	}
+	return content, nil
+}
+
+// LoadClientCertificate parses a PEM or PFX certificate with its private key
+// and checks it is currently valid.
+func LoadClientCertificate(certificate string, password string) ([]*x509.Certificate, crypto.PrivateKey, error) {
+	content, err := readCertificateSource(certificate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var certs []*x509.Certificate
+	var key crypto.PrivateKey
+	if block, _ := pem.Decode(content); block != nil {
+		certs, key, err = confidential.CertFromPEM(content, password)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		pfxKey, cert, caCerts, err := pkcs12.DecodeChain(content, password)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not decode PFX certificate: %w", err)
+		}
+		certs = append([]*x509.Certificate{cert}, caCerts...)
+		key = pfxKey
+	}
+
+	if len(certs) == 0 {
+		return nil, nil, fmt.Errorf("no certificate found")
+	}
+	if key == nil {
+		return nil, nil, fmt.Errorf("no private key found along the certificate")
+	}
+
+	// Entra ID authenticates with the certificate of the private key, which is not always the first of the chain
+	cert, err := certificateOfKey(certs, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if now.After(cert.NotAfter) {
+		return nil, nil, fmt.Errorf("certificate %s expired on %s", cert.Subject, cert.NotAfter.Format(time.RFC3339))
+	}
+	if now.Before(cert.NotBefore) {
+		return nil, nil, fmt.Errorf("certificate %s is not valid before %s", cert.Subject, cert.NotBefore.Format(time.RFC3339))
+	}
+
+	return certs, key, nil
+}
+
+// certificateOfKey returns the certificate of certs holding the public key of the private key.
+func certificateOfKey(certs []*x509.Certificate, key crypto.PrivateKey) (*x509.Certificate, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key %T", key)
+	}
+	public, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key %T", signer.Public())
+	}
+	for _, cert := range certs {
+		if public.Equal(cert.PublicKey) {
+			return cert, nil
+		}
+	}
+	return nil, fmt.Errorf("no certificate found for the private key")
+}
@@ -0,0 +1,144 @@
+package azdo
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func newTestCertificate(t *testing.T, notBefore time.Time, notAfter time.Time) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "helloasso-test"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func encodeTestPEM(cert *x509.Certificate, key *rsa.PrivateKey) string {
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return string(certPEM) + string(keyPEM)
+}
+
+func TestLoadClientCertificate_PEM(t *testing.T) {
+	cert, key := newTestCertificate(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	inline := encodeTestPEM(cert, key)
+
+	certPath := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(certPath, []byte(inline), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, certificate := range map[string]string{"inline": inline, "path": certPath} {
+		t.Run(name, func(t *testing.T) {
+			certs, privateKey, err := LoadClientCertificate(certificate, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(certs) != 1 || certs[0].Subject.CommonName != "helloasso-test" {
+				t.Errorf("unexpected certificates %v", certs)
+			}
+			if privateKey == nil {
+				t.Error("expected a private key")
+			}
+		})
+	}
+}
+
+func TestLoadClientCertificate_PFX(t *testing.T) {
+	cert, key := newTestCertificate(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	pfx, err := pkcs12.Modern.Encode(key, cert, nil, "pfx-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pfxPath := filepath.Join(t.TempDir(), "cert.pfx")
+	if err := os.WriteFile(pfxPath, pfx, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, certificate := range map[string]string{"inline": base64.StdEncoding.EncodeToString(pfx), "path": pfxPath} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := LoadClientCertificate(certificate, "pfx-password"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, _, err := LoadClientCertificate(certificate, "wrong-password"); err == nil {
+				t.Error("expected an error with a wrong password")
+			}
+		})
+	}
+}
+
+func TestLoadClientCertificate_Invalid(t *testing.T) {
+	expiredCert, expiredKey := newTestCertificate(t, time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour))
+	futureCert, futureKey := newTestCertificate(t, time.Now().Add(24*time.Hour), time.Now().Add(48*time.Hour))
+
+	cases := map[string]string{
+		"expired":      encodeTestPEM(expiredCert, expiredKey),
+		"not yet":      encodeTestPEM(futureCert, futureKey),
+		"missing file": filepath.Join(t.TempDir(), "missing.pem"),
+		"garbage":      "not a certificate",
+	}
+	for name, certificate := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := LoadClientCertificate(certificate, ""); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoadClientCertificate_Chain(t *testing.T) {
+	validCert, validKey := newTestCertificate(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	expiredCert, expiredKey := newTestCertificate(t, time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour))
+	chain := func(certs []*x509.Certificate, key *rsa.PrivateKey) string {
+		var content []byte
+		for _, cert := range certs {
+			content = append(content, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+		}
+		return string(content) + string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	}
+
+	// The certificate of the private key is listed after another one of the chain
+	for name, tc := range map[string]struct {
+		certificate string
+		expectError bool
+	}{
+		"valid key certificate":   {certificate: chain([]*x509.Certificate{expiredCert, validCert}, validKey)},
+		"expired key certificate": {certificate: chain([]*x509.Certificate{validCert, expiredCert}, expiredKey), expectError: true},
+		"no key certificate":      {certificate: chain([]*x509.Certificate{validCert}, expiredKey), expectError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := LoadClientCertificate(tc.certificate, "")
+			if tc.expectError != (err != nil) {
+				t.Errorf("expected error %t, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package azdo
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	CLOUD_PUBLIC          string = "public"
+	CLOUD_USGOVERNMENT    string = "usgovernment"
+	CLOUD_CHINA           string = "china"
+	CLOUD_CUSTOM          string = "custom"
+	AZ_DEVOPS_RESOURCE_ID string = "499b84ac-1321-427f-aa17-267ca6975798"
+)
+
+// Cloud holds the endpoints depending on the Azure cloud the
+// tenants live in. Azure Devops Services only runs in the public cloud, so the
+// sovereign clouds change the AzureAD authority host and Microsoft Graph endpoint
+// while their tenants sign in to the public Azure Devops. Other Azure Devops hosts
+// need the custom cloud.
+type Cloud struct {
+	Name                  string
+	AuthorityHost         string
+	GraphEndpoint         string
+	AzureDevopsResourceID string
+	AzureDevopsURL        string
+	AzureDevopsVsspsURL   string
+	AzureDevopsAuditURL   string
+}
+
+var CLOUDS = map[string]Cloud{
+	CLOUD_PUBLIC: {
+		Name:                  CLOUD_PUBLIC,
+		AuthorityHost:         "https://login.microsoftonline.com",
+		GraphEndpoint:         "https://graph.microsoft.com",
+		AzureDevopsResourceID: AZ_DEVOPS_RESOURCE_ID,
+		AzureDevopsURL:        "https://dev.azure.com",
+		AzureDevopsVsspsURL:   "https://vssps.dev.azure.com",
+		AzureDevopsAuditURL:   "https://auditservice.dev.azure.com",
+	},
+	CLOUD_USGOVERNMENT: {
+		Name:                  CLOUD_USGOVERNMENT,
+		AuthorityHost:         "https://login.microsoftonline.us",
+		GraphEndpoint:         "https://graph.microsoft.us",
+		AzureDevopsResourceID: AZ_DEVOPS_RESOURCE_ID,
+		AzureDevopsURL:        "https://dev.azure.com",
+		AzureDevopsVsspsURL:   "https://vssps.dev.azure.com",
+		AzureDevopsAuditURL:   "https://auditservice.dev.azure.com",
+	},
+	CLOUD_CHINA: {
+		Name:                  CLOUD_CHINA,
+		AuthorityHost:         "https://login.chinacloudapi.cn",
+		GraphEndpoint:         "https://microsoftgraph.chinacloudapi.cn",
+		AzureDevopsResourceID: AZ_DEVOPS_RESOURCE_ID,
+		AzureDevopsURL:        "https://dev.azure.com",
+		AzureDevopsVsspsURL:   "https://vssps.dev.azure.com",
+		AzureDevopsAuditURL:   "https://auditservice.dev.azure.com",
+	},
+}
+
+// NewCloud returns the endpoints of the named cloud.
+// Overrides are only accepted for the custom cloud, where unset ones keep the public cloud value.
+func NewCloud(name string, overrides Cloud) (Cloud, error) {
+	if name == "" {
+		name = CLOUD_PUBLIC
+	}
+	if name != CLOUD_CUSTOM {
+		cloud, ok := CLOUDS[name]
+		if !ok {
+			return Cloud{}, fmt.Errorf("unsupported environment %q, must be one of public, usgovernment, china or custom", name)
+		}
+		if overrides != (Cloud{}) {
+			return Cloud{}, fmt.Errorf("endpoints can only be overridden with environment %q", CLOUD_CUSTOM)
+		}
+		return cloud, nil
+	}
+
+	public := CLOUDS[CLOUD_PUBLIC]
+	return Cloud{
+		Name:                  CLOUD_CUSTOM,
+		AuthorityHost:         strings.TrimSuffix(firstNonEmpty(overrides.AuthorityHost, public.AuthorityHost), "/"),
+		GraphEndpoint:         strings.TrimSuffix(firstNonEmpty(overrides.GraphEndpoint, public.GraphEndpoint), "/"),
+		AzureDevopsResourceID: firstNonEmpty(overrides.AzureDevopsResourceID, public.AzureDevopsResourceID),
+		AzureDevopsURL:        strings.TrimSuffix(firstNonEmpty(overrides.AzureDevopsURL, public.AzureDevopsURL), "/"),
+		AzureDevopsVsspsURL:   strings.TrimSuffix(firstNonEmpty(overrides.AzureDevopsVsspsURL, public.AzureDevopsVsspsURL), "/"),
+		AzureDevopsAuditURL:   strings.TrimSuffix(firstNonEmpty(overrides.AzureDevopsAuditURL, public.AzureDevopsAuditURL), "/"),
+	}, nil
+}
+
+func (c Cloud) AzureDevopsScope() string {
+	return c.AzureDevopsResourceID + "/.default"
+}
+
+func (c Cloud) GraphScope() string {
+	return c.GraphEndpoint + "/.default"
+}
+
+func (c Cloud) ApplicationsEndpoint() string {
+	return c.GraphEndpoint + "/v1.0/applications"
+}
+
+func (c Cloud) OrganizationURL(organization string) string {
+	return c.AzureDevopsURL + "/" + organization
+}
+
+func (c Cloud) PatEndpoint(organization string) string {
+	return c.AzureDevopsVsspsURL + "/" + organization + "/_apis/tokens/pats"
+}
+
+func (c Cloud) AuditLogEndpoint(organization string) string {
+	return c.AzureDevopsAuditURL + "/" + organization + "/_apis/audit/auditlog"
+}
+
+// AuthorityURL completes a bare tenant ID or domain with the cloud authority host,
+// full authority URLs are returned as is.
+func (c Cloud) AuthorityURL(authority string) string {
+	if authority == "" || strings.Contains(authority, "://") {
+		return authority
+	}
+	return c.AuthorityHost + "/" + authority
+}
+
+// InstanceDiscovery tells whether MSAL can validate the authority host against
+// Microsoft instance discovery, which does not know custom hosts.
+func (c Cloud) InstanceDiscovery() bool {
+	return c.Name != CLOUD_CUSTOM
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
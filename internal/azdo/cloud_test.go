@@ -0,0 +1,56 @@
+package azdo
+
+import "testing"
+
+func TestNewCloud(t *testing.T) {
+	cloud, err := NewCloud("", Cloud{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cloud.Name != CLOUD_PUBLIC || !cloud.InstanceDiscovery() {
+		t.Errorf("expected public cloud by default, got %+v", cloud)
+	}
+
+	cloud, err = NewCloud(CLOUD_CHINA, Cloud{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cloud.AuthorityURL("128c6ba1-f30f-4176-87d4-a93c61ae4ef0"); got != "https://login.chinacloudapi.cn/128c6ba1-f30f-4176-87d4-a93c61ae4ef0" {
+		t.Errorf("unexpected authority %q", got)
+	}
+	if got := cloud.AuthorityURL("https://login.microsoftonline.com/tenant"); got != "https://login.microsoftonline.com/tenant" {
+		t.Errorf("full authority URL should be kept, got %q", got)
+	}
+	if got := cloud.GraphScope(); got != "https://microsoftgraph.chinacloudapi.cn/.default" {
+		t.Errorf("unexpected Graph scope %q", got)
+	}
+	if got := cloud.PatEndpoint("myorganization"); got != "https://vssps.dev.azure.com/myorganization/_apis/tokens/pats" {
+		t.Errorf("sovereign clouds should keep the public Azure Devops, got %q", got)
+	}
+
+	cloud, err = NewCloud(CLOUD_CUSTOM, Cloud{AuthorityHost: "https://127.0.0.1:8443/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cloud.AuthorityHost != "https://127.0.0.1:8443" || cloud.GraphEndpoint != "https://graph.microsoft.com" || cloud.InstanceDiscovery() {
+		t.Errorf("unexpected custom cloud %+v", cloud)
+	}
+	if got := cloud.AzureDevopsScope(); got != AZ_DEVOPS_RESOURCE_ID+"/.default" {
+		t.Errorf("unexpected Azure Devops scope %q", got)
+	}
+	if got := cloud.PatEndpoint("myorganization"); got != "https://vssps.dev.azure.com/myorganization/_apis/tokens/pats" {
+		t.Errorf("unexpected PAT endpoint %q", got)
+	}
+	if got := cloud.AuditLogEndpoint("myorganization"); got != "https://auditservice.dev.azure.com/myorganization/_apis/audit/auditlog" {
+		t.Errorf("unexpected audit log endpoint %q", got)
+	}
+}
+
+func TestNewCloud_Errors(t *testing.T) {
+	if _, err := NewCloud("mars", Cloud{}); err == nil {
+		t.Error("expected an error for an unknown environment")
+	}
+	if _, err := NewCloud(CLOUD_PUBLIC, Cloud{AuthorityHost: "https://127.0.0.1"}); err == nil {
+		t.Error("expected an error when overriding endpoints outside the custom environment")
+	}
+}
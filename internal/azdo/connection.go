@@ -0,0 +1,67 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ConnectionUser is an identity as described by the connectionData API.
+type ConnectionUser struct {
+	ID                  string `json:"id"`
+	Descriptor          string `json:"descriptor"`
+	SubjectDescriptor   string `json:"subjectDescriptor"`
+	ProviderDisplayName string `json:"providerDisplayName"`
+}
+
+// ConnectionData describes who a token authenticates as in an organization.
+type ConnectionData struct {
+	AuthenticatedUser ConnectionUser `json:"authenticatedUser"`
+	AuthorizedUser    ConnectionUser `json:"authorizedUser"`
+	InstanceID        string         `json:"instanceId"`
+	DeploymentType    string         `json:"deploymentType"`
+}
+
+// GetConnectionData calls the connectionData API of an organization URL (https://dev.azure.com/<organization>),
+// the cheapest call telling whether a token for scope is accepted.
+func GetConnectionData(ctx context.Context, client *http.Client, organizationURL string, tokens TokenProvider, scope string) (*ConnectionData, error) {
+	token, err := tokens.GetToken(ctx, []string{scope})
+	if err != nil {
+		return nil, fmt.Errorf("could not get token: %w", err)
+	}
+
+	api_req, err := http.NewRequestWithContext(ctx, http.MethodGet, organizationURL+"/_apis/connectionData", nil)
+	if err != nil {
+		return nil, err
+	}
+	api_req.Header.Set("Authorization", "Bearer "+token.Token)
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(api_req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, &APIError{Method: http.MethodGet, StatusCode: res.StatusCode, Message: string(resBody)}
+	}
+
+	connectionData := &ConnectionData{}
+	if err := json.Unmarshal(resBody, connectionData); err != nil {
+		return nil, err
+	}
+	// Anonymous calls may succeed too, without an authenticated user
+	if connectionData.AuthenticatedUser.ID == "" {
+		return nil, fmt.Errorf("token was not accepted by %s, connected anonymously", organizationURL)
+	}
+	return connectionData, nil
+}
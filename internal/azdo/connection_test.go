@@ -0,0 +1,45 @@
+package azdo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetConnectionData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/myorganization/_apis/connectionData" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch r.Header.Get("Authorization") {
+		case "Bearer ad-token":
+			_, _ = w.Write([]byte(`{"authenticatedUser":{"id":"user-id","descriptor":"aad.user","providerDisplayName":"User"},"instanceId":"org-id"}`))
+		case "Bearer anonymous":
+			_, _ = w.Write([]byte(`{"authenticatedUser":{},"instanceId":"org-id"}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	connectionData, err := GetConnectionData(ctx, server.Client(), server.URL+"/myorganization", staticTokenProvider{token: "ad-token"}, "scope")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if connectionData.AuthenticatedUser.ID != "user-id" || connectionData.AuthenticatedUser.ProviderDisplayName != "User" || connectionData.InstanceID != "org-id" {
+		t.Errorf("unexpected connection data %+v", connectionData)
+	}
+
+	if _, err := GetConnectionData(ctx, server.Client(), server.URL+"/myorganization", staticTokenProvider{token: "anonymous"}, "scope"); err == nil {
+		t.Error("expected an error when connected anonymously")
+	}
+
+	_, err = GetConnectionData(ctx, server.Client(), server.URL+"/myorganization", staticTokenProvider{token: "expired"}, "scope")
+	apiErr := &APIError{}
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a 401 API error, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	MANAGED_IDENTITY_IMDS_ENDPOINT           string = "http://169.254.169.254/metadata/identity/oauth2/token"
+	MANAGED_IDENTITY_IMDS_API_VERSION        string = "2018-02-01"
+	MANAGED_IDENTITY_APP_SERVICE_API_VERSION string = "2019-08-01"
+)
+
+// ManagedIdentityConfig describes which managed identity to get a token for and where.
+// Empty Endpoint falls back on the App Service IDENTITY_ENDPOINT, then on IMDS.
+type ManagedIdentityConfig struct {
+	ClientID string
+	Endpoint string
+}
+
+type managedIdentityTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	// IMDS and App Service both return a unix timestamp, as a string
+	ExpiresOn string `json:"expires_on"`
+	Resource  string `json:"resource"`
+	TokenType string `json:"token_type"`
+}
+
+// scopeToResource turns a v2 scope ("<resource>/.default") into the v1 resource
+// expected by managed identity endpoints.
+func scopeToResource(apiScope string) string {
+	return strings.TrimSuffix(apiScope, "/.default")
+}
+
+// getManagedIdentityToken requests a token for resource from the IMDS endpoint,
+// or from the App Service identity endpoint when IDENTITY_HEADER is set.
+func getManagedIdentityToken(ctx context.Context, client *http.Client, cfg ManagedIdentityConfig, resource string) (*managedIdentityTokenResponse, error) {
+	endpoint := firstNonEmpty(cfg.Endpoint, os.Getenv("IDENTITY_ENDPOINT"), MANAGED_IDENTITY_IMDS_ENDPOINT)
+	identityHeader := os.Getenv("IDENTITY_HEADER")
+
+	tokenURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid managed identity endpoint: %w", err)
+	}
+	query := tokenURL.Query()
+	query.Set("resource", resource)
+	if cfg.ClientID != "" {
+		query.Set("client_id", cfg.ClientID)
+	}
+	if identityHeader != "" {
+		query.Set("api-version", MANAGED_IDENTITY_APP_SERVICE_API_VERSION)
+	} else {
+		query.Set("api-version", MANAGED_IDENTITY_IMDS_API_VERSION)
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	mi_req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if identityHeader != "" {
+		mi_req.Header.Set("X-IDENTITY-HEADER", identityHeader)
+	} else {
+		mi_req.Header.Set("Metadata", "true")
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(mi_req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("managed identity endpoint returned %d, message %v", res.StatusCode, string(body))
+	}
+
+	tokenResponse := &managedIdentityTokenResponse{}
+	if err := json.Unmarshal(body, tokenResponse); err != nil {
+		return nil, fmt.Errorf("could not decode managed identity token response: %w", err)
+	}
+	if tokenResponse.AccessToken == "" {
+		return nil, fmt.Errorf("managed identity endpoint returned no token")
+	}
+	return tokenResponse, nil
+}
+
+// ManagedIdentityTokenProvider gets tokens for the managed identity of the Azure VM or App Service running terraform.
+type ManagedIdentityTokenProvider struct {
+	Config     ManagedIdentityConfig
+	HTTPClient *http.Client
+}
+
+var _ TokenProvider = &ManagedIdentityTokenProvider{}
+
+func (p *ManagedIdentityTokenProvider) GetToken(ctx context.Context, scopes []string) (AccessToken, error) {
+
+	tflog.Info(ctx, "ManagedIdentityTokenProvider.GetToken")
+	if len(scopes) != 1 {
+		return AccessToken{}, fmt.Errorf("managed identity tokens are requested for a single scope, got %d", len(scopes))
+	}
+	result, err := getManagedIdentityToken(ctx, p.HTTPClient, p.Config, scopeToResource(scopes[0]))
+	if err != nil {
+		return AccessToken{}, err
+	}
+
+	token := AccessToken{Token: result.AccessToken}
+	if expiresOn, err := strconv.ParseInt(result.ExpiresOn, 10, 64); err == nil {
+		token.ExpiresOn = time.Unix(expiresOn, 0)
+	}
+	return token, nil
+}
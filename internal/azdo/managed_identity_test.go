@@ -0,0 +1,82 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetManagedIdentityToken_Imds(t *testing.T) {
+	t.Setenv("IDENTITY_ENDPOINT", "")
+	t.Setenv("IDENTITY_HEADER", "")
+
+	imds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Metadata"); got != "true" {
+			t.Errorf("expected Metadata header, got %q", got)
+		}
+		if got := r.URL.Query().Get("api-version"); got != MANAGED_IDENTITY_IMDS_API_VERSION {
+			t.Errorf("unexpected api-version %q", got)
+		}
+		if got := r.URL.Query().Get("resource"); got != "499b84ac-1321-427f-aa17-267ca6975798" {
+			t.Errorf("unexpected resource %q", got)
+		}
+		if got := r.URL.Query().Get("client_id"); got != "user-assigned-id" {
+			t.Errorf("unexpected client_id %q", got)
+		}
+		_, _ = w.Write([]byte(`{"access_token":"imds-token","expires_on":"1700000000","token_type":"Bearer"}`))
+	}))
+	defer imds.Close()
+
+	token, err := getManagedIdentityToken(context.Background(), imds.Client(), ManagedIdentityConfig{
+		ClientID: "user-assigned-id",
+		Endpoint: imds.URL,
+	}, scopeToResource(AZ_DEVOPS_RESOURCE_ID+"/.default"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.AccessToken != "imds-token" || token.ExpiresOn != "1700000000" {
+		t.Errorf("unexpected token %+v", token)
+	}
+}
+
+func TestGetManagedIdentityToken_AppService(t *testing.T) {
+	appService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-IDENTITY-HEADER"); got != "secret-header" {
+			t.Errorf("expected X-IDENTITY-HEADER, got %q", got)
+		}
+		if got := r.URL.Query().Get("api-version"); got != MANAGED_IDENTITY_APP_SERVICE_API_VERSION {
+			t.Errorf("unexpected api-version %q", got)
+		}
+		if r.URL.Query().Has("client_id") {
+			t.Error("client_id should not be sent for system-assigned identity")
+		}
+		_, _ = w.Write([]byte(`{"access_token":"app-service-token","expires_on":"1700000000"}`))
+	}))
+	defer appService.Close()
+
+	t.Setenv("IDENTITY_ENDPOINT", appService.URL)
+	t.Setenv("IDENTITY_HEADER", "secret-header")
+
+	token, err := getManagedIdentityToken(context.Background(), appService.Client(), ManagedIdentityConfig{}, "https://graph.microsoft.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.AccessToken != "app-service-token" {
+		t.Errorf("unexpected token %+v", token)
+	}
+}
+
+func TestGetManagedIdentityToken_Error(t *testing.T) {
+	t.Setenv("IDENTITY_HEADER", "")
+
+	imds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_request","error_description":"Identity not found"}`))
+	}))
+	defer imds.Close()
+
+	if _, err := getManagedIdentityToken(context.Background(), imds.Client(), ManagedIdentityConfig{Endpoint: imds.URL}, "https://graph.microsoft.com"); err == nil {
+		t.Error("expected an error")
+	}
+}
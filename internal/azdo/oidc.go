@@ -0,0 +1,121 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	OIDC_AUDIENCE                    string = "api://AzureADTokenExchange"
+	OIDC_AZURE_PIPELINES_API_VERSION string = "7.1"
+)
+
+// OidcConfig describes where to find the federated token exchanged against an
+// AzureAD access token. Empty fields fall back on the environment variables
+// set by GitHub Actions, Azure Pipelines and Azure workload identity.
+type OidcConfig struct {
+	TokenFilePath       string
+	RequestURL          string
+	RequestToken        string
+	ServiceConnectionID string
+}
+
+type oidcTokenResponse struct {
+	// GitHub Actions
+	Value string `json:"value"`
+	// Azure Pipelines
+	OidcToken string `json:"oidcToken"`
+}
+
+// WithEnvDefaults completes unset fields from the CI environment.
+func (c OidcConfig) WithEnvDefaults() OidcConfig {
+	return OidcConfig{
+		TokenFilePath:       firstNonEmpty(c.TokenFilePath, os.Getenv("AZURE_FEDERATED_TOKEN_FILE")),
+		RequestURL:          firstNonEmpty(c.RequestURL, os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL"), os.Getenv("SYSTEM_OIDCREQUESTURI")),
+		RequestToken:        firstNonEmpty(c.RequestToken, os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN"), os.Getenv("SYSTEM_ACCESSTOKEN")),
+		ServiceConnectionID: firstNonEmpty(c.ServiceConnectionID, os.Getenv("AZURESUBSCRIPTION_SERVICE_CONNECTION_ID"), os.Getenv("ARM_OIDC_AZURE_SERVICE_CONNECTION_ID")),
+	}
+}
+
+// GetOidcAssertion returns the federated token, read from the token file if any,
+// otherwise requested from the pipeline OIDC endpoint.
+// When a service connection ID is set the endpoint is called the Azure Pipelines way,
+// otherwise the GitHub Actions way.
+func GetOidcAssertion(ctx context.Context, client *http.Client, cfg OidcConfig) (string, error) {
+	if cfg.TokenFilePath != "" {
+		content, err := os.ReadFile(cfg.TokenFilePath)
+		if err != nil {
+			return "", fmt.Errorf("could not read OIDC token file: %w", err)
+		}
+		token := strings.TrimSpace(string(content))
+		if token == "" {
+			return "", fmt.Errorf("OIDC token file %s is empty", cfg.TokenFilePath)
+		}
+		return token, nil
+	}
+
+	if cfg.RequestURL == "" {
+		return "", fmt.Errorf("no OIDC token source, set oidc_token_file_path or oidc_request_url (or run in a pipeline exposing them)")
+	}
+	if cfg.RequestToken == "" {
+		return "", fmt.Errorf("oidc_request_token is required to call the OIDC request URL")
+	}
+
+	requestURL, err := url.Parse(cfg.RequestURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid OIDC request URL: %w", err)
+	}
+	query := requestURL.Query()
+	method := http.MethodGet
+	if cfg.ServiceConnectionID != "" {
+		method = http.MethodPost
+		query.Set("api-version", OIDC_AZURE_PIPELINES_API_VERSION)
+		query.Set("serviceConnectionId", cfg.ServiceConnectionID)
+	} else {
+		query.Set("audience", OIDC_AUDIENCE)
+	}
+	requestURL.RawQuery = query.Encode()
+
+	oidc_req, err := http.NewRequestWithContext(ctx, method, requestURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	oidc_req.Header.Set("Authorization", "Bearer "+cfg.RequestToken)
+	oidc_req.Header.Set("Accept", "application/json")
+	if method == http.MethodPost {
+		oidc_req.Header.Set("Content-Type", "application/json")
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(oidc_req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != 200 {
+		return "", fmt.Errorf("OIDC request URL returned %d, message %v", res.StatusCode, string(body))
+	}
+
+	tokenResponse := &oidcTokenResponse{}
+	if err := json.Unmarshal(body, tokenResponse); err != nil {
+		return "", fmt.Errorf("could not decode OIDC token response: %w", err)
+	}
+	token := firstNonEmpty(tokenResponse.Value, tokenResponse.OidcToken)
+	if token == "" {
+		return "", fmt.Errorf("OIDC request URL returned no token")
+	}
+	return token, nil
+}
@@ -0,0 +1,123 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetOidcAssertion_TokenFile(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("federated-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := GetOidcAssertion(context.Background(), nil, OidcConfig{TokenFilePath: tokenFile, RequestURL: "http://unused"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "federated-token" {
+		t.Errorf("expected trimmed token from file, got %q", token)
+	}
+}
+
+func TestGetOidcAssertion_GithubActions(t *testing.T) {
+	issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("expected GET, got %s", r.Method)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer request-token" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		if got := r.URL.Query().Get("audience"); got != OIDC_AUDIENCE {
+			t.Errorf("unexpected audience %q", got)
+		}
+		if got := r.URL.Query().Get("existing"); got != "1" {
+			t.Errorf("existing query parameter lost, got %q", got)
+		}
+		_, _ = w.Write([]byte(`{"count":1,"value":"github-token"}`))
+	}))
+	defer issuer.Close()
+
+	token, err := GetOidcAssertion(context.Background(), issuer.Client(), OidcConfig{
+		RequestURL:   issuer.URL + "/token?existing=1",
+		RequestToken: "request-token",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "github-token" {
+		t.Errorf("expected github-token, got %q", token)
+	}
+}
+
+func TestGetOidcAssertion_AzurePipelines(t *testing.T) {
+	issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if got := r.URL.Query().Get("serviceConnectionId"); got != "connection-id" {
+			t.Errorf("unexpected serviceConnectionId %q", got)
+		}
+		if got := r.URL.Query().Get("api-version"); got != OIDC_AZURE_PIPELINES_API_VERSION {
+			t.Errorf("unexpected api-version %q", got)
+		}
+		_, _ = w.Write([]byte(`{"oidcToken":"pipelines-token"}`))
+	}))
+	defer issuer.Close()
+
+	token, err := GetOidcAssertion(context.Background(), issuer.Client(), OidcConfig{
+		RequestURL:          issuer.URL,
+		RequestToken:        "system-access-token",
+		ServiceConnectionID: "connection-id",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "pipelines-token" {
+		t.Errorf("expected pipelines-token, got %q", token)
+	}
+}
+
+func TestGetOidcAssertion_Errors(t *testing.T) {
+	issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer issuer.Close()
+
+	cases := map[string]OidcConfig{
+		"no source":        {},
+		"no request token": {RequestURL: issuer.URL},
+		"missing file":     {TokenFilePath: filepath.Join(t.TempDir(), "missing")},
+		"issuer rejects":   {RequestURL: issuer.URL, RequestToken: "request-token"},
+	}
+	for name, cfg := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := GetOidcAssertion(context.Background(), issuer.Client(), cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestOidcConfig_WithEnvDefaults(t *testing.T) {
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "")
+	t.Setenv("SYSTEM_OIDCREQUESTURI", "https://dev.azure.com/org/oidc")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "")
+	t.Setenv("SYSTEM_ACCESSTOKEN", "system-access-token")
+
+	cfg := OidcConfig{ServiceConnectionID: "explicit"}.WithEnvDefaults()
+	if cfg.RequestURL != "https://dev.azure.com/org/oidc" {
+		t.Errorf("unexpected RequestURL %q", cfg.RequestURL)
+	}
+	if cfg.RequestToken != "system-access-token" {
+		t.Errorf("unexpected RequestToken %q", cfg.RequestToken)
+	}
+	if cfg.ServiceConnectionID != "explicit" {
+		t.Errorf("explicit value should win over env, got %q", cfg.ServiceConnectionID)
+	}
+}
@@ -0,0 +1,42 @@
+package azdo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var authorizationURIRegexp = regexp.MustCompile(`authorization_uri="?https?://[^/"\s,]+/([0-9a-fA-F-]{36})`)
+
+// DiscoverOrganizationTenant returns the ID of the AzureAD tenant backing an
+// Azure Devops organization, read from the challenge of an unauthenticated call.
+func DiscoverOrganizationTenant(ctx context.Context, client *http.Client, organizationURL string) (string, error) {
+	tenant_req, err := http.NewRequestWithContext(ctx, http.MethodGet, organizationURL+"/_apis/connectionData", nil)
+	if err != nil {
+		return "", err
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(tenant_req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if tenant := strings.TrimSpace(strings.Split(res.Header.Get("X-VSS-ResourceTenant"), ",")[0]); tenant != "" && tenant != "00000000-0000-0000-0000-000000000000" {
+		return tenant, nil
+	}
+	for _, challenge := range res.Header.Values("WWW-Authenticate") {
+		if match := authorizationURIRegexp.FindStringSubmatch(challenge); match != nil {
+			return match[1], nil
+		}
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("organization %s not found", organizationURL)
+	}
+	return "", fmt.Errorf("could not discover the tenant of organization %s (status %d), set authority explicitly", organizationURL, res.StatusCode)
+}
@@ -0,0 +1,55 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDiscoverOrganizationTenant(t *testing.T) {
+	const tenant = "128c6ba1-f30f-4176-87d4-a93c61ae4ef0"
+
+	cases := map[string]func(w http.ResponseWriter){
+		"resource tenant header": func(w http.ResponseWriter) {
+			w.Header().Set("X-VSS-ResourceTenant", tenant)
+			w.WriteHeader(http.StatusUnauthorized)
+		},
+		"bearer challenge": func(w http.ResponseWriter) {
+			w.Header().Add("WWW-Authenticate", "Bearer authorization_uri=https://login.microsoftonline.com/"+tenant)
+			w.Header().Add("WWW-Authenticate", `Basic realm="https://tfsprodweu5.visualstudio.com/"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		},
+	}
+	for name, respond := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/myorganization/_apis/connectionData" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != "" {
+					t.Error("tenant discovery must be unauthenticated")
+				}
+				respond(w)
+			}))
+			defer server.Close()
+
+			got, err := DiscoverOrganizationTenant(context.Background(), server.Client(), server.URL+"/myorganization")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tenant {
+				t.Errorf("expected tenant %s, got %s", tenant, got)
+			}
+		})
+	}
+}
+
+func TestDiscoverOrganizationTenant_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	if _, err := DiscoverOrganizationTenant(context.Background(), server.Client(), server.URL+"/unknown"); err == nil {
+		t.Error("expected an error")
+	}
+}
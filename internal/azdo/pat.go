@@ -0,0 +1,235 @@
+package azdo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const PAT_API_VERSION string = "7.0-preview.1"
+
+// PAT API errors of a creation the organization PAT policy forbids, as returned in 'patTokenError'
+const (
+	PAT_ERROR_FULL_SCOPE_POLICY_VIOLATION string = "fullScopePatPolicyViolation"
+	PAT_ERROR_GLOBAL_POLICY_VIOLATION     string = "globalPatPolicyViolation"
+	PAT_ERROR_LIFESPAN_POLICY_VIOLATION   string = "patLifespanPolicyViolation"
+)
+
+// PatToken is a PAT as returned by the PAT lifecycle API, Token is only set on creation.
+type PatToken struct {
+	DisplayName     string    `json:"displayName"`
+	ValidTo         time.Time `json:"validTo"`
+	Scope           string    `json:"scope"`
+	TargetAccounts  []string  `json:"targetAccounts"`
+	ValidFrom       time.Time `json:"validFrom"`
+	AuthorizationID string    `json:"authorizationId"`
+	Token           string    `json:"token"`
+}
+
+type PatTokenResult struct {
+	PatToken      PatToken `json:"patToken"`
+	PatTokenError string   `json:"patTokenError"`
+}
+
+type PagedPatTokens struct {
+	ContinuationToken string     `json:"continuationToken"`
+	PatTokens         []PatToken `json:"patTokens"`
+}
+
+type CreatePatRequest struct {
+	DisplayName string    `json:"displayName"`
+	Scope       string    `json:"scope"`
+	ValidTo     time.Time `json:"validTo"`
+	AllOrgs     bool      `json:"allOrgs"`
+}
+
+type UpdatePatRequest struct {
+	AuthorizationID string    `json:"authorizationId"`
+	DisplayName     string    `json:"displayName"`
+	Scope           string    `json:"scope"`
+	ValidTo         time.Time `json:"validTo"`
+	AllOrgs         bool      `json:"allOrgs"`
+}
+
+type ListPatsOptions struct {
+	// DisplayFilterOption is one of active, revoked, expired or all (default: active)
+	DisplayFilterOption string
+}
+
+// PatClient manages the PATs of the identity its tokens are issued for.
+type PatClient interface {
+	Create(ctx context.Context, req CreatePatRequest) (*PatToken, error)
+	Get(ctx context.Context, authorizationID string) (*PatToken, error)
+	List(ctx context.Context, opts ListPatsOptions) ([]PatToken, error)
+	Update(ctx context.Context, req UpdatePatRequest) (*PatToken, error)
+	Revoke(ctx context.Context, authorizationID string) error
+}
+
+// APIError is returned when an Azure Devops API answers with an unexpected status.
+type APIError struct {
+	Method     string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API returned %d, message %v", e.Method, e.StatusCode, e.Message)
+}
+
+// IsPatNotFound tells whether err is the PAT API answer for a PAT that does not exist, or no longer does
+// as revoked or expired. Some answers are a 404, others a 'Token not found' message with another status.
+func IsPatNotFound(err error) bool {
+	apiErr := &APIError{}
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusNotFound || strings.Contains(strings.ToLower(apiErr.Message), "token not found")
+}
+
+// IsPatPolicyViolation tells whether err is the PAT API answer for a creation the organization PAT policy forbids.
+func IsPatPolicyViolation(err error) bool {
+	apiErr := &APIError{}
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Message {
+	case PAT_ERROR_FULL_SCOPE_POLICY_VIOLATION, PAT_ERROR_GLOBAL_POLICY_VIOLATION, PAT_ERROR_LIFESPAN_POLICY_VIOLATION:
+		return true
+	}
+	return false
+}
+
+type patClient struct {
+	httpClient *http.Client
+	endpoint   string
+	tokens     TokenProvider
+	scope      string
+}
+
+var _ PatClient = &patClient{}
+
+// NewPatClient returns a client of the PAT lifecycle API at endpoint
+// (https://vssps.dev.azure.com/<organization>/_apis/tokens/pats), authenticated with tokens for scope.
+func NewPatClient(httpClient *http.Client, endpoint string, tokens TokenProvider, scope string) PatClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &patClient{
+		httpClient: httpClient,
+		endpoint:   endpoint,
+		tokens:     tokens,
+		scope:      scope,
+	}
+}
+
+func (c *patClient) do(ctx context.Context, method string, query url.Values, body any, out any) error {
+	query.Set("api-version", PAT_API_VERSION)
+	return doAPI(ctx, c.httpClient, c.tokens, c.scope, method, c.endpoint+"?"+query.Encode(), body, out)
+}
+
+// doAPI calls an Azure Devops JSON API with a bearer token for scope, out is left untouched on empty answers.
+func doAPI(ctx context.Context, httpClient *http.Client, tokens TokenProvider, scope string, method string, apiURL string, body any, out any) error {
+	token, err := tokens.GetToken(ctx, []string{scope})
+	if err != nil {
+		return fmt.Errorf("could not get token: %w", err)
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		json_data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewBuffer(json_data)
+	}
+
+	api_req, err := http.NewRequestWithContext(ctx, method, apiURL, reqBody)
+	if err != nil {
+		return err
+	}
+	api_req.Header.Set("Authorization", "Bearer "+token.Token)
+	if body != nil {
+		api_req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := httpClient.Do(api_req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != 200 && res.StatusCode != 204 {
+		return &APIError{Method: method, StatusCode: res.StatusCode, Message: string(resBody)}
+	}
+	if out == nil || len(resBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resBody, out)
+}
+
+// patTokenResult checks the PAT API did not answer an error in the body of a successful response.
+func patTokenResult(method string, result *PatTokenResult) (*PatToken, error) {
+	if result.PatTokenError != "" && result.PatTokenError != "none" {
+		return nil, &APIError{Method: method, StatusCode: 200, Message: result.PatTokenError}
+	}
+	return &result.PatToken, nil
+}
+
+func (c *patClient) Create(ctx context.Context, req CreatePatRequest) (*PatToken, error) {
+	result := &PatTokenResult{}
+	if err := c.do(ctx, http.MethodPost, url.Values{}, req, result); err != nil {
+		return nil, err
+	}
+	return patTokenResult(http.MethodPost, result)
+}
+
+func (c *patClient) Get(ctx context.Context, authorizationID string) (*PatToken, error) {
+	result := &PatTokenResult{}
+	if err := c.do(ctx, http.MethodGet, url.Values{"authorizationId": {authorizationID}}, nil, result); err != nil {
+		return nil, err
+	}
+	return patTokenResult(http.MethodGet, result)
+}
+
+func (c *patClient) List(ctx context.Context, opts ListPatsOptions) ([]PatToken, error) {
+	query := url.Values{}
+	if opts.DisplayFilterOption != "" {
+		query.Set("displayFilterOption", opts.DisplayFilterOption)
+	}
+
+	tokens := []PatToken{}
+	for {
+		page := &PagedPatTokens{}
+		if err := c.do(ctx, http.MethodGet, query, nil, page); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, page.PatTokens...)
+		if page.ContinuationToken == "" {
+			return tokens, nil
+		}
+		query.Set("continuationToken", page.ContinuationToken)
+	}
+}
+
+func (c *patClient) Update(ctx context.Context, req UpdatePatRequest) (*PatToken, error) {
+	result := &PatTokenResult{}
+	if err := c.do(ctx, http.MethodPut, url.Values{}, req, result); err != nil {
+		return nil, err
+	}
+	return patTokenResult(http.MethodPut, result)
+}
+
+func (c *patClient) Revoke(ctx context.Context, authorizationID string) error {
+	return c.do(ctx, http.MethodDelete, url.Values{"authorizationId": {authorizationID}}, nil, nil)
+}
@@ -0,0 +1,83 @@
+package azdo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PAT_CHECK_POLL_INTERVAL is the delay between two attempts of WaitPatUsable
+const PAT_CHECK_POLL_INTERVAL time.Duration = time.Second
+
+// patCheckAPIs maps scope areas to an organization API their PATs can call,
+// PATs without any of them are checked against connectionData.
+var patCheckAPIs = map[string]string{
+	"vso.build":   "_apis/build/definitions?$top=1&api-version=7.0",
+	"vso.code":    "_apis/git/repositories?api-version=7.0",
+	"vso.project": "_apis/projects?$top=1&api-version=7.0",
+}
+
+// PatCheckURL returns the URL of an API of organizationURL (https://dev.azure.com/<organization>)
+// that a PAT with scopes (space separated) is allowed to call.
+func PatCheckURL(organizationURL, scopes string) string {
+	for _, scope := range strings.Fields(scopes) {
+		area, _, _ := strings.Cut(scope, "_")
+		if api, ok := patCheckAPIs[area]; ok {
+			return organizationURL + "/" + api
+		}
+	}
+	return organizationURL + "/_apis/connectionData"
+}
+
+// CheckPat calls url with pat as basic auth password, it returns nil once the PAT is accepted.
+func CheckPat(ctx context.Context, client *http.Client, url, pat string) error {
+	check_req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	check_req.SetBasicAuth("", pat)
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(check_req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return &APIError{Method: http.MethodGet, StatusCode: res.StatusCode, Message: string(resBody)}
+	}
+	// Rejected PATs may be redirected to the sign-in page instead of a 401
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "application/json") {
+		return fmt.Errorf("PAT was not accepted by %s, got a %q response", url, res.Header.Get("Content-Type"))
+	}
+	return nil
+}
+
+// WaitPatUsable polls url with pat every interval until it is accepted, as new PATs take
+// a while to propagate. It returns the last error once timeout is over.
+func WaitPatUsable(ctx context.Context, client *http.Client, url, pat string, timeout, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		err := CheckPat(ctx, client, url, pat)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("PAT not usable after %s, last error: %w", timeout, err)
+		case <-time.After(interval):
+		}
+	}
+}
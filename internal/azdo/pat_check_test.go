@@ -0,0 +1,58 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPatCheckURL(t *testing.T) {
+	for scopes, expected := range map[string]string{
+		"vso.code_write":              "https://dev.azure.com/org/_apis/git/repositories?api-version=7.0",
+		"vso.packaging vso.build":     "https://dev.azure.com/org/_apis/build/definitions?$top=1&api-version=7.0",
+		"vso.project_manage":          "https://dev.azure.com/org/_apis/projects?$top=1&api-version=7.0",
+		"vso.packaging_write":         "https://dev.azure.com/org/_apis/connectionData",
+		"app_token":                   "https://dev.azure.com/org/_apis/connectionData",
+		"vso.variablegroups_read vso": "https://dev.azure.com/org/_apis/connectionData",
+	} {
+		if url := PatCheckURL("https://dev.azure.com/org", scopes); url != expected {
+			t.Errorf("PatCheckURL(%q) = %q, expected %q", scopes, url, expected)
+		}
+	}
+}
+
+func TestWaitPatUsable(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pat, _ := r.BasicAuth()
+		switch {
+		case pat == "login-page":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html>Sign in</html>"))
+		case pat == "new-pat" && calls.Add(1) > 2:
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_, _ = w.Write([]byte(`{"count":0,"value":[]}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	if err := WaitPatUsable(ctx, server.Client(), server.URL, "new-pat", time.Second, 10*time.Millisecond); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls.Load())
+	}
+
+	if err := WaitPatUsable(ctx, server.Client(), server.URL, "revoked-pat", 50*time.Millisecond, 10*time.Millisecond); err == nil {
+		t.Error("expected an error for a PAT never accepted")
+	}
+	if err := CheckPat(ctx, server.Client(), server.URL, "login-page"); err == nil {
+		t.Error("expected an error when redirected to the sign-in page")
+	}
+}
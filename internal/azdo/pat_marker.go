@@ -0,0 +1,42 @@
+package azdo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// PAT_MARKER_PREFIX starts the marker appended to the display name of the PATs the provider issues,
+// the PAT API has no other field to tag them with.
+const PAT_MARKER_PREFIX string = "[tf-helloasso"
+
+var patMarkerRegexp = regexp.MustCompile(`^(.*) \[tf-helloasso(?::([0-9a-f]+))?\]$`)
+
+// PatMarkerKey identifies the PATs issued for the same attributes, e.g. the ones forcing a new PAT.
+func PatMarkerKey(parts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(hash[:6])
+}
+
+// MarkedDisplayName returns the display name of a PAT named name being issued for key,
+// until it is saved into state and renamed with ManagedDisplayName.
+func MarkedDisplayName(name, key string) string {
+	return name + " " + PAT_MARKER_PREFIX + ":" + key + "]"
+}
+
+// ManagedDisplayName returns the display name of a PAT named name saved into state,
+// marked as issued by the provider without the key of its creation.
+func ManagedDisplayName(name string) string {
+	return name + " " + PAT_MARKER_PREFIX + "]"
+}
+
+// ParsePatMarker returns the name and key of a display name built by MarkedDisplayName, the key
+// is empty for ManagedDisplayName. ok is false for PATs not issued by the provider.
+func ParsePatMarker(displayName string) (name, key string, ok bool) {
+	match := patMarkerRegexp.FindStringSubmatch(displayName)
+	if match == nil {
+		return displayName, "", false
+	}
+	return match[1], match[2], true
+}
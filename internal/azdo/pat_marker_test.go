@@ -0,0 +1,29 @@
+package azdo
+
+import "testing"
+
+func TestParsePatMarker(t *testing.T) {
+	key := PatMarkerKey("gitops", "vso.code")
+	if len(key) != 12 || key == PatMarkerKey("gitops", "vso.code_write") || key != PatMarkerKey("gitops", "vso.code") {
+		t.Errorf("unexpected marker key %q", key)
+	}
+
+	displayName := MarkedDisplayName("gitops [prod]", key)
+	if displayName != "gitops [prod] [tf-helloasso:"+key+"]" {
+		t.Errorf("unexpected display name %q", displayName)
+	}
+	if name, parsedKey, ok := ParsePatMarker(displayName); !ok || name != "gitops [prod]" || parsedKey != key {
+		t.Errorf("ParsePatMarker(%q) = %q, %q, %v", displayName, name, parsedKey, ok)
+	}
+
+	displayName = ManagedDisplayName("gitops [prod]")
+	if name, parsedKey, ok := ParsePatMarker(displayName); displayName != "gitops [prod] [tf-helloasso]" || !ok || name != "gitops [prod]" || parsedKey != "" {
+		t.Errorf("ParsePatMarker(%q) = %q, %q, %v", displayName, name, parsedKey, ok)
+	}
+
+	for _, displayName := range []string{"gitops", "gitops [tf-helloasso:]", "gitops [tf-helloasso:XYZ]", "[tf-helloasso:abc]"} {
+		if name, _, ok := ParsePatMarker(displayName); ok || name != displayName {
+			t.Errorf("expected %q not to be marked, got %q, %v", displayName, name, ok)
+		}
+	}
+}
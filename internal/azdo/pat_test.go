@@ -0,0 +1,178 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type staticTokenProvider struct {
+	token string
+}
+
+func (p staticTokenProvider) GetToken(_ context.Context, _ []string) (AccessToken, error) {
+	return AccessToken{Token: p.token, ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func newTestPatClient(t *testing.T, handler http.HandlerFunc) PatClient {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer ad-token" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		if got := r.URL.Query().Get("api-version"); got != PAT_API_VERSION {
+			t.Errorf("unexpected api-version %q", got)
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return NewPatClient(server.Client(), server.URL+"/myorganization/_apis/tokens/pats", staticTokenProvider{token: "ad-token"}, AZ_DEVOPS_RESOURCE_ID+"/.default")
+}
+
+func TestPatClient_Create(t *testing.T) {
+	validTo := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	client := newTestPatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		req := &CreatePatRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Fatal(err)
+		}
+		if req.DisplayName != "gitops" || req.Scope != "vso.code" || !req.ValidTo.Equal(validTo) {
+			t.Errorf("unexpected request %+v", req)
+		}
+		_, _ = w.Write([]byte(`{"patToken":{"displayName":"gitops","authorizationId":"pat-id","token":"secret","validTo":"2030-01-02T03:04:05Z"},"patTokenError":"none"}`))
+	})
+
+	patToken, err := client.Create(context.Background(), CreatePatRequest{DisplayName: "gitops", Scope: "vso.code", ValidTo: validTo})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if patToken.AuthorizationID != "pat-id" || patToken.Token != "secret" || !patToken.ValidTo.Equal(validTo) {
+		t.Errorf("unexpected PAT %+v", patToken)
+	}
+}
+
+func TestPatClient_CreatePatTokenError(t *testing.T) {
+	client := newTestPatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"patToken":null,"patTokenError":"fullScopePatPolicyViolation"}`))
+	})
+
+	_, err := client.Create(context.Background(), CreatePatRequest{DisplayName: "gitops", Scope: "app_token"})
+	apiErr := &APIError{}
+	if !errors.As(err, &apiErr) || apiErr.Message != "fullScopePatPolicyViolation" {
+		t.Errorf("expected a PAT token error, got %v", err)
+	}
+}
+
+func TestPatClient_GetUpdateRevoke(t *testing.T) {
+	client := newTestPatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if got := r.URL.Query().Get("authorizationId"); got != "pat-id" {
+				t.Errorf("unexpected authorizationId %q", got)
+			}
+			_, _ = w.Write([]byte(`{"patToken":{"displayName":"gitops","authorizationId":"pat-id"}}`))
+		case http.MethodPut:
+			req := &UpdatePatRequest{}
+			if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+				t.Fatal(err)
+			}
+			_, _ = w.Write([]byte(`{"patToken":{"displayName":"` + req.DisplayName + `","authorizationId":"` + req.AuthorizationID + `"}}`))
+		case http.MethodDelete:
+			if got := r.URL.Query().Get("authorizationId"); got != "pat-id" {
+				t.Errorf("unexpected authorizationId %q", got)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	patToken, err := client.Get(context.Background(), "pat-id")
+	if err != nil || patToken.DisplayName != "gitops" {
+		t.Errorf("unexpected Get result %+v, %v", patToken, err)
+	}
+	patToken, err = client.Update(context.Background(), UpdatePatRequest{AuthorizationID: "pat-id", DisplayName: "renamed"})
+	if err != nil || patToken.DisplayName != "renamed" {
+		t.Errorf("unexpected Update result %+v, %v", patToken, err)
+	}
+	if err := client.Revoke(context.Background(), "pat-id"); err != nil {
+		t.Errorf("unexpected Revoke error %v", err)
+	}
+}
+
+func TestPatClient_ListFollowsContinuationToken(t *testing.T) {
+	client := newTestPatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("displayFilterOption"); got != "all" {
+			t.Errorf("unexpected displayFilterOption %q", got)
+		}
+		if r.URL.Query().Get("continuationToken") == "" {
+			_, _ = w.Write([]byte(`{"continuationToken":"next","patTokens":[{"authorizationId":"first"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"continuationToken":"","patTokens":[{"authorizationId":"second"}]}`))
+	})
+
+	patTokens, err := client.List(context.Background(), ListPatsOptions{DisplayFilterOption: "all"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(patTokens) != 2 || patTokens[0].AuthorizationID != "first" || patTokens[1].AuthorizationID != "second" {
+		t.Errorf("unexpected PATs %+v", patTokens)
+	}
+}
+
+func TestPatClient_APIError(t *testing.T) {
+	client := newTestPatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`unauthorized`))
+	})
+
+	err := client.Revoke(context.Background(), "pat-id")
+	apiErr := &APIError{}
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a 401 APIError, got %v", err)
+	}
+}
+
+func TestIsPatNotFound(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected bool
+	}{
+		{err: &APIError{Method: http.MethodDelete, StatusCode: http.StatusNotFound}, expected: true},
+		{err: &APIError{Method: http.MethodDelete, StatusCode: http.StatusBadRequest, Message: `{"message":"Token not found."}`}, expected: true},
+		{err: fmt.Errorf("could not revoke: %w", &APIError{Method: http.MethodGet, StatusCode: http.StatusNotFound}), expected: true},
+		{err: &APIError{Method: http.MethodDelete, StatusCode: http.StatusUnauthorized, Message: "unauthorized"}, expected: false},
+		{err: errors.New("token not found"), expected: false},
+		{err: nil, expected: false},
+	} {
+		if got := IsPatNotFound(tc.err); got != tc.expected {
+			t.Errorf("IsPatNotFound(%v) = %v, expected %v", tc.err, got, tc.expected)
+		}
+	}
+}
+
+func TestIsPatPolicyViolation(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected bool
+	}{
+		{err: &APIError{Method: http.MethodPost, StatusCode: http.StatusOK, Message: PAT_ERROR_FULL_SCOPE_POLICY_VIOLATION}, expected: true},
+		{err: fmt.Errorf("could not create: %w", &APIError{Method: http.MethodPost, StatusCode: http.StatusOK, Message: PAT_ERROR_LIFESPAN_POLICY_VIOLATION}), expected: true},
+		{err: &APIError{Method: http.MethodPost, StatusCode: http.StatusOK, Message: "invalidScope"}, expected: false},
+		{err: errors.New("patLifespanPolicyViolation"), expected: false},
+		{err: nil, expected: false},
+	} {
+		if got := IsPatPolicyViolation(tc.err); got != tc.expected {
+			t.Errorf("IsPatPolicyViolation(%v) = %v, expected %v", tc.err, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package azdo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// FULL_ACCESS_SCOPE grants every scope, it is not part of the catalog.
+const FULL_ACCESS_SCOPE string = "app_token"
+
+const (
+	SCOPE_LEVEL_READ   string = "read"
+	SCOPE_LEVEL_WRITE  string = "write"
+	SCOPE_LEVEL_MANAGE string = "manage"
+)
+
+var SCOPE_LEVELS = []string{SCOPE_LEVEL_READ, SCOPE_LEVEL_WRITE, SCOPE_LEVEL_MANAGE}
+
+// Scope describes an Azure Devops PAT scope, see
+// https://learn.microsoft.com/en-us/azure/devops/integrate/get-started/authentication/oauth#scopes
+type Scope struct {
+	Name        string `json:"name"`
+	Area        string `json:"area"`
+	Level       string `json:"level"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Implies lists the scopes granted along with this one, directly or not
+	Implies []string `json:"implies"`
+}
+
+//go:embed scopes.json
+var scopesJSON []byte
+
+var scopeCatalog = mustLoadScopes(scopesJSON)
+
+// ScopeCatalog returns the known Azure Devops PAT scopes, sorted by name.
+func ScopeCatalog() []Scope {
+	scopes := make([]Scope, 0, len(scopeCatalog))
+	for _, scope := range scopeCatalog {
+		scope.Implies = append([]string{}, scope.Implies...)
+		scopes = append(scopes, scope)
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].Name < scopes[j].Name })
+	return scopes
+}
+
+// LookupScope returns the catalog entry of the scope name.
+func LookupScope(name string) (Scope, bool) {
+	scope, ok := scopeCatalog[name]
+	if ok {
+		scope.Implies = append([]string{}, scope.Implies...)
+	}
+	return scope, ok
+}
+
+func mustLoadScopes(data []byte) map[string]Scope {
+	scopes, err := loadScopes(data)
+	if err != nil {
+		panic(fmt.Sprintf("invalid scope catalog: %v", err))
+	}
+	return scopes
+}
+
+// loadScopes parses a scope catalog whose implied scopes are only the direct ones,
+// and expands them to every scope they imply in turn.
+func loadScopes(data []byte) (map[string]Scope, error) {
+	var list []Scope
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	scopes := map[string]Scope{}
+	for _, scope := range list {
+		if _, ok := scopes[scope.Name]; ok {
+			return nil, fmt.Errorf("%q is listed twice", scope.Name)
+		}
+		scopes[scope.Name] = scope
+	}
+
+	for name, scope := range scopes {
+		implied := map[string]bool{}
+		pending := append([]string{}, scope.Implies...)
+		for len(pending) > 0 {
+			impliedName := pending[0]
+			pending = pending[1:]
+			if impliedName == name {
+				return nil, fmt.Errorf("%q implies itself", name)
+			}
+			if implied[impliedName] {
+				continue
+			}
+			impliedScope, ok := scopes[impliedName]
+			if !ok {
+				return nil, fmt.Errorf("%q implies unknown scope %q", name, impliedName)
+			}
+			implied[impliedName] = true
+			pending = append(pending, impliedScope.Implies...)
+		}
+
+		scope.Implies = make([]string, 0, len(implied))
+		for impliedName := range implied {
+			scope.Implies = append(scope.Implies, impliedName)
+		}
+		sort.Strings(scope.Implies)
+		scopes[name] = scope
+	}
+	return scopes, nil
+}
@@ -0,0 +1,48 @@
+package azdo
+
+import (
+	"reflect"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestScopeCatalog(t *testing.T) {
+	nameRegexp := regexp.MustCompile(`^vso\.[a-z]+(_[a-z]+)*$`)
+	scopes := ScopeCatalog()
+	if len(scopes) == 0 {
+		t.Fatal("empty scope catalog")
+	}
+	for _, scope := range scopes {
+		if !nameRegexp.MatchString(scope.Name) || scope.Area == "" || scope.Title == "" || scope.Description == "" {
+			t.Errorf("incomplete scope %+v", scope)
+		}
+		if !slices.Contains(SCOPE_LEVELS, scope.Level) {
+			t.Errorf("unexpected level of scope %+v", scope)
+		}
+	}
+
+	scope, ok := LookupScope("vso.code_full")
+	if !ok || scope.Area != "code" || scope.Level != SCOPE_LEVEL_MANAGE {
+		t.Fatalf("unexpected vso.code_full scope %+v", scope)
+	}
+	if expected := []string{"vso.code", "vso.code_manage", "vso.code_write"}; !reflect.DeepEqual(scope.Implies, expected) {
+		t.Errorf("expected vso.code_full to imply %v, got %v", expected, scope.Implies)
+	}
+	if _, ok := LookupScope("vso.unknown"); ok {
+		t.Error("expected vso.unknown not to be found")
+	}
+}
+
+func TestLoadScopes(t *testing.T) {
+	for _, data := range []string{
+		`[{"name": "vso.a"}, {"name": "vso.a"}]`,
+		`[{"name": "vso.a", "implies": ["vso.b"]}]`,
+		`[{"name": "vso.a", "implies": ["vso.b"]}, {"name": "vso.b", "implies": ["vso.a"]}]`,
+		`{}`,
+	} {
+		if _, err := loadScopes([]byte(data)); err == nil {
+			t.Errorf("expected catalog %s to be rejected", data)
+		}
+	}
+}
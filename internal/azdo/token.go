@@ -0,0 +1,51 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/confidential"
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/public"
+)
+
+// AccessToken is an AzureAD access token along with its expiration.
+type AccessToken struct {
+	Token     string
+	ExpiresOn time.Time
+}
+
+// TokenProvider acquires AzureAD access tokens for a set of scopes.
+type TokenProvider interface {
+	GetToken(ctx context.Context, scopes []string) (AccessToken, error)
+}
+
+// AppConfig identifies the app registration tokens are requested through.
+type AppConfig struct {
+	ClientID string
+	// Authority is an authority URL, or a tenant ID completed with the cloud authority host
+	Authority  string
+	Cloud      Cloud
+	HTTPClient *http.Client
+}
+
+func (c AppConfig) newPublicClient() (public.Client, error) {
+	options := []public.Option{
+		public.WithAuthority(c.Cloud.AuthorityURL(c.Authority)),
+		public.WithInstanceDiscovery(c.Cloud.InstanceDiscovery()),
+	}
+	if c.HTTPClient != nil {
+		options = append(options, public.WithHTTPClient(c.HTTPClient))
+	}
+	return public.New(c.ClientID, options...)
+}
+
+func (c AppConfig) newConfidentialClient(cred confidential.Credential) (confidential.Client, error) {
+	options := []confidential.Option{
+		confidential.WithInstanceDiscovery(c.Cloud.InstanceDiscovery()),
+	}
+	if c.HTTPClient != nil {
+		options = append(options, confidential.WithHTTPClient(c.HTTPClient))
+	}
+	return confidential.New(c.Cloud.AuthorityURL(c.Authority), c.ClientID, cred, options...)
+}
@@ -0,0 +1,176 @@
+package azdo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const TOKEN_ADMIN_API_VERSION string = "7.1-preview.1"
+
+// PatPolicy is the PAT policy an organization enforces, as set by the Azure Devops administrators
+// of its tenant. MaxLifespanDays is 0 when PATs may be valid for as long as Azure Devops allows.
+type PatPolicy struct {
+	MaxLifespanDays   int64 `json:"maxPatLifespanInDays"`
+	RestrictFullScope bool  `json:"restrictFullScopePatCreation"`
+	RestrictGlobal    bool  `json:"restrictGlobalPatCreation"`
+	RestrictCreation  bool  `json:"restrictPatCreation"`
+}
+
+// CheckScopes returns an error when the policy forbids to create a PAT with the whitespace separated scopes.
+func (p PatPolicy) CheckScopes(scopes string) error {
+	if !p.RestrictFullScope {
+		return nil
+	}
+	for _, scope := range strings.Fields(scopes) {
+		if scope == FULL_ACCESS_SCOPE {
+			return fmt.Errorf("the organization forbids full-scoped PATs (%s), list the scopes needed instead", FULL_ACCESS_SCOPE)
+		}
+	}
+	return nil
+}
+
+// MaxValidTo returns the latest expiration date allowed for a PAT created at now, and false when there is no maximum.
+func (p PatPolicy) MaxValidTo(now time.Time) (time.Time, bool) {
+	if p.MaxLifespanDays <= 0 {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, 0, int(p.MaxLifespanDays)), true
+}
+
+// SessionToken is a PAT of an organization user as listed by the token admin API.
+type SessionToken struct {
+	AuthorizationID string    `json:"authorizationId"`
+	DisplayName     string    `json:"displayName"`
+	Scope           string    `json:"scope"`
+	ValidFrom       time.Time `json:"validFrom"`
+	ValidTo         time.Time `json:"validTo"`
+	IsValid         bool      `json:"isValid"`
+}
+
+type PagedSessionTokens struct {
+	ContinuationToken string         `json:"continuationToken"`
+	Value             []SessionToken `json:"value"`
+}
+
+// TokenRevocation asks to revoke the PAT or session token of an authorization.
+type TokenRevocation struct {
+	AuthorizationID string `json:"authorizationId"`
+}
+
+// TokenAdminClient manages the PATs of an organization, authenticated as one of its administrators.
+type TokenAdminClient interface {
+	// GetPatPolicy reads the PAT policy from the undocumented 'policies' endpoint, see IsPatPolicyUnavailable
+	GetPatPolicy(ctx context.Context) (*PatPolicy, error)
+	// ListUserPats returns the PATs of the user subjectDescriptor, following continuation tokens
+	ListUserPats(ctx context.Context, subjectDescriptor string) ([]SessionToken, error)
+	// RevokeAuthorizations revokes the PATs of authorizationIDs, whoever owns them
+	RevokeAuthorizations(ctx context.Context, authorizationIDs []string) error
+}
+
+type tokenAdminClient struct {
+	httpClient *http.Client
+	endpoint   string
+	tokens     TokenProvider
+	scope      string
+}
+
+var _ TokenAdminClient = &tokenAdminClient{}
+
+// NewTokenAdminClient returns a client of the token admin API at endpoint
+// (https://vssps.dev.azure.com/<organization>/_apis/tokenadmin), authenticated with tokens for scope.
+func NewTokenAdminClient(httpClient *http.Client, endpoint string, tokens TokenProvider, scope string) TokenAdminClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &tokenAdminClient{
+		httpClient: httpClient,
+		endpoint:   endpoint,
+		tokens:     tokens,
+		scope:      scope,
+	}
+}
+
+// TokenAdminEndpoint derives the token admin API endpoint from the PAT lifecycle API endpoint of the same organization.
+func TokenAdminEndpoint(patEndpoint string) string {
+	return strings.TrimSuffix(patEndpoint, "/tokens/pats") + "/tokenadmin"
+}
+
+func (c *tokenAdminClient) do(ctx context.Context, method string, resource string, query url.Values, body any, out any) error {
+	query.Set("api-version", TOKEN_ADMIN_API_VERSION)
+	return doAPI(ctx, c.httpClient, c.tokens, c.scope, method, c.endpoint+"/"+resource+"?"+query.Encode(), body, out)
+}
+
+// IsPatPolicyUnavailable tells whether err is the token admin API answer of an organization that does not serve
+// the PAT policy. Its 'policies' endpoint is a preview that Microsoft does not document, so callers skip the
+// policy checks rather than fail.
+func IsPatPolicyUnavailable(err error) bool {
+	apiErr := &APIError{}
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// patPolicyAnswer is the answer of the 'policies' endpoint. Every field is required, as an answer of another
+// shape would otherwise read as a policy enforcing nothing.
+type patPolicyAnswer struct {
+	MaxLifespanDays   *int64 `json:"maxPatLifespanInDays"`
+	RestrictFullScope *bool  `json:"restrictFullScopePatCreation"`
+	RestrictGlobal    *bool  `json:"restrictGlobalPatCreation"`
+	RestrictCreation  *bool  `json:"restrictPatCreation"`
+}
+
+func (c *tokenAdminClient) GetPatPolicy(ctx context.Context) (*PatPolicy, error) {
+	answer := &patPolicyAnswer{}
+	if err := c.do(ctx, http.MethodGet, "policies", url.Values{}, nil, answer); err != nil {
+		return nil, err
+	}
+	missing := []string{}
+	if answer.MaxLifespanDays == nil {
+		missing = append(missing, "maxPatLifespanInDays")
+	}
+	if answer.RestrictFullScope == nil {
+		missing = append(missing, "restrictFullScopePatCreation")
+	}
+	if answer.RestrictGlobal == nil {
+		missing = append(missing, "restrictGlobalPatCreation")
+	}
+	if answer.RestrictCreation == nil {
+		missing = append(missing, "restrictPatCreation")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("unexpected PAT policy answer without %s, the undocumented 'policies' endpoint may have changed", strings.Join(missing, ", "))
+	}
+	return &PatPolicy{
+		MaxLifespanDays:   *answer.MaxLifespanDays,
+		RestrictFullScope: *answer.RestrictFullScope,
+		RestrictGlobal:    *answer.RestrictGlobal,
+		RestrictCreation:  *answer.RestrictCreation,
+	}, nil
+}
+
+func (c *tokenAdminClient) ListUserPats(ctx context.Context, subjectDescriptor string) ([]SessionToken, error) {
+	tokens := []SessionToken{}
+	query := url.Values{}
+	for {
+		page := &PagedSessionTokens{}
+		if err := c.do(ctx, http.MethodGet, "personalaccesstokens/"+url.PathEscape(subjectDescriptor), query, nil, page); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, page.Value...)
+		if page.ContinuationToken == "" {
+			return tokens, nil
+		}
+		query.Set("continuationToken", page.ContinuationToken)
+	}
+}
+
+func (c *tokenAdminClient) RevokeAuthorizations(ctx context.Context, authorizationIDs []string) error {
+	revocations := make([]TokenRevocation, 0, len(authorizationIDs))
+	for _, authorizationID := range authorizationIDs {
+		revocations = append(revocations, TokenRevocation{AuthorizationID: authorizationID})
+	}
+	return c.do(ctx, http.MethodPost, "revocations", url.Values{}, revocations, nil)
+}
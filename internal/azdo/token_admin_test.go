@@ -0,0 +1,70 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPatPolicy(t *testing.T) {
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	if _, ok := (PatPolicy{}).MaxValidTo(now); ok {
+		t.Error("expected no maximum without lifespan policy")
+	}
+	maxValidTo, ok := PatPolicy{MaxLifespanDays: 30}.MaxValidTo(now)
+	if expected := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC); !ok || !maxValidTo.Equal(expected) {
+		t.Errorf("expected maximum %s, got %s, %v", expected, maxValidTo, ok)
+	}
+
+	for _, test := range []struct {
+		policy PatPolicy
+		scopes string
+		valid  bool
+	}{
+		{PatPolicy{}, "app_token", true},
+		{PatPolicy{RestrictFullScope: true}, "vso.code vso.packaging", true},
+		{PatPolicy{RestrictFullScope: true}, "app_token", false},
+	} {
+		if err := test.policy.CheckScopes(test.scopes); (err == nil) != test.valid {
+			t.Errorf("CheckScopes(%q) with %+v returned %v", test.scopes, test.policy, err)
+		}
+	}
+}
+
+func TestTokenAdminEndpoint(t *testing.T) {
+	if endpoint := TokenAdminEndpoint("https://vssps.dev.azure.com/myorganization/_apis/tokens/pats"); endpoint != "https://vssps.dev.azure.com/myorganization/_apis/tokenadmin" {
+		t.Errorf("unexpected token admin endpoint %s", endpoint)
+	}
+}
+
+func TestTokenAdminClient_GetPatPolicy(t *testing.T) {
+	answer := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/myorganization/_apis/tokenadmin/policies" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(answer))
+	}))
+	defer server.Close()
+	client := NewTokenAdminClient(server.Client(), server.URL+"/myorganization/_apis/tokenadmin", staticTokenProvider{token: "ad-token"}, "scope")
+	ctx := context.Background()
+
+	answer = `{"maxPatLifespanInDays":30,"restrictFullScopePatCreation":true,"restrictGlobalPatCreation":false,"restrictPatCreation":false}`
+	policy, err := client.GetPatPolicy(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *policy != (PatPolicy{MaxLifespanDays: 30, RestrictFullScope: true}) {
+		t.Errorf("unexpected policy %+v", *policy)
+	}
+
+	// Answers of another shape must not read as a policy enforcing nothing
+	for _, answer = range []string{"", `{}`, `{"value":[{"maxPatLifespanInDays":30}],"count":1}`, `{"maxPatLifespanInDays":30,"restrictFullScopePatCreation":true}`} {
+		if policy, err := client.GetPatPolicy(ctx); err == nil {
+			t.Errorf("expected an error for answer %q, got %+v", answer, *policy)
+		}
+	}
+}
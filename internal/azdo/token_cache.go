@@ -0,0 +1,73 @@
+package azdo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TOKEN_CACHE_MIN_VALIDITY is how long a cached token must still be valid to be reused.
+const TOKEN_CACHE_MIN_VALIDITY time.Duration = 5 * time.Minute
+
+// TokenCache shares the access tokens acquired in a provider process between the
+// resources using the same credentials, so that they are not acquired again. It lives
+// in memory only: plan and apply run separate processes and acquire their own.
+type TokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]AccessToken
+}
+
+func (c *TokenCache) get(key string) (AccessToken, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	token, ok := c.tokens[key]
+	if !ok || time.Until(token.ExpiresOn) < TOKEN_CACHE_MIN_VALIDITY {
+		return AccessToken{}, false
+	}
+	return token, true
+}
+
+func (c *TokenCache) put(key string, token AccessToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tokens == nil {
+		c.tokens = map[string]AccessToken{}
+	}
+	c.tokens[key] = token
+}
+
+// TokenCacheKey identifies the credentials tokens are acquired with, secrets are hashed.
+func TokenCacheKey(parts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+// CachedTokenProvider returns the tokens of Cache while they are valid, and acquires
+// them through Provider otherwise, e.g. skipping the public client switch of PasswordTokenProvider.
+type CachedTokenProvider struct {
+	Provider TokenProvider
+	Cache    *TokenCache
+	// Key identifies the credentials of Provider, see TokenCacheKey
+	Key string
+}
+
+var _ TokenProvider = &CachedTokenProvider{}
+
+func (p *CachedTokenProvider) GetToken(ctx context.Context, scopes []string) (AccessToken, error) {
+	key := p.Key + " " + strings.Join(scopes, " ")
+	if token, ok := p.Cache.get(key); ok {
+		return token, nil
+	}
+
+	token, err := p.Provider.GetToken(ctx, scopes)
+	if err != nil {
+		return AccessToken{}, err
+	}
+	p.Cache.put(key, token)
+	return token, nil
+}
@@ -0,0 +1,54 @@
+package azdo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type countingTokenProvider struct {
+	calls     int
+	expiresIn time.Duration
+}
+
+func (p *countingTokenProvider) GetToken(_ context.Context, scopes []string) (AccessToken, error) {
+	p.calls++
+	return AccessToken{Token: scopes[0], ExpiresOn: time.Now().Add(p.expiresIn)}, nil
+}
+
+func TestCachedTokenProvider(t *testing.T) {
+	ctx := context.Background()
+	cache := &TokenCache{}
+	provider := &countingTokenProvider{expiresIn: time.Hour}
+	tokens := &CachedTokenProvider{Provider: provider, Cache: cache, Key: TokenCacheKey("password", "user@myorganization.com", "usersuperpassword")}
+
+	for i := 0; i < 2; i++ {
+		token, err := tokens.GetToken(ctx, []string{"devops"})
+		if err != nil || token.Token != "devops" {
+			t.Fatalf("unexpected token %+v, error %v", token, err)
+		}
+	}
+	if provider.calls != 1 {
+		t.Errorf("expected the second token to come from the cache, got %d calls", provider.calls)
+	}
+
+	// Other scopes and other credentials are acquired again
+	tokens.GetToken(ctx, []string{"graph"})
+	other := &CachedTokenProvider{Provider: provider, Cache: cache, Key: TokenCacheKey("password", "user@myorganization.com", "newpassword")}
+	other.GetToken(ctx, []string{"devops"})
+	if provider.calls != 3 {
+		t.Errorf("expected 3 calls, got %d", provider.calls)
+	}
+}
+
+func TestCachedTokenProvider_Expiring(t *testing.T) {
+	ctx := context.Background()
+	provider := &countingTokenProvider{expiresIn: TOKEN_CACHE_MIN_VALIDITY - time.Second}
+	tokens := &CachedTokenProvider{Provider: provider, Cache: &TokenCache{}, Key: "key"}
+
+	tokens.GetToken(ctx, []string{"devops"})
+	tokens.GetToken(ctx, []string{"devops"})
+	if provider.calls != 2 {
+		t.Errorf("expected tokens about to expire to be acquired again, got %d calls", provider.calls)
+	}
+}
@@ -0,0 +1,62 @@
+package azdo
+
+import (
+	"context"
+
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/confidential"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// ConfidentialTokenProvider gets tokens for a confidential app registration, authenticated
+// with a secret, a certificate or a federated assertion.
+type ConfidentialTokenProvider struct {
+	App        AppConfig
+	Credential confidential.Credential
+}
+
+var _ TokenProvider = &ConfidentialTokenProvider{}
+
+func NewClientSecretTokenProvider(app AppConfig, secret string) (*ConfidentialTokenProvider, error) {
+	cred, err := confidential.NewCredFromSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfidentialTokenProvider{App: app, Credential: cred}, nil
+}
+
+func NewClientCertificateTokenProvider(app AppConfig, certificate string, password string) (*ConfidentialTokenProvider, error) {
+	certs, key, err := LoadClientCertificate(certificate, password)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := confidential.NewCredFromCert(certs, key)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfidentialTokenProvider{App: app, Credential: cred}, nil
+}
+
+// NewOidcTokenProvider exchanges a federated token from the CI against AzureAD tokens.
+func NewOidcTokenProvider(app AppConfig, cfg OidcConfig) *ConfidentialTokenProvider {
+	cfg = cfg.WithEnvDefaults()
+	cred := confidential.NewCredFromAssertionCallback(func(ctx context.Context, _ confidential.AssertionRequestOptions) (string, error) {
+		return GetOidcAssertion(ctx, app.HTTPClient, cfg)
+	})
+	return &ConfidentialTokenProvider{App: app, Credential: cred}
+}
+
+func (p *ConfidentialTokenProvider) GetToken(ctx context.Context, scopes []string) (AccessToken, error) {
+
+	tflog.Info(ctx, "ConfidentialTokenProvider.GetToken")
+	confidentialClientApp, err := p.App.newConfidentialClient(p.Credential)
+	if err != nil {
+		return AccessToken{}, err
+	}
+
+	result, err := confidentialClientApp.AcquireTokenByCredential(ctx, scopes)
+	if err != nil {
+		return AccessToken{}, err
+	}
+
+	return AccessToken{Token: result.AccessToken, ExpiresOn: result.ExpiresOn}, nil
+}
@@ -0,0 +1,74 @@
+package azdo
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const SWITCH_PRIVATE_PUBLIC_DEFAULT_WAIT time.Duration = 7 * time.Second
+
+// PasswordTokenProvider gets tokens for an Azure Devops user through a public app registration.
+type PasswordTokenProvider struct {
+	App      AppConfig
+	Username string
+	Password string
+	// SwitchPrivatePublic calls the local AZ CLI to make the app public only while getting the token
+	SwitchPrivatePublic     bool
+	SwitchPrivatePublicWait time.Duration
+}
+
+var _ TokenProvider = &PasswordTokenProvider{}
+
+func (p *PasswordTokenProvider) GetToken(ctx context.Context, scopes []string) (AccessToken, error) {
+
+	tflog.Info(ctx, "PasswordTokenProvider.GetToken")
+	// We add a workaround here for more security: make app public only while we get the token
+	// Since there is no AcquireTokenByUsernamePassword for Confidential App yet
+	azCmd := []string{"az", "ad", "app", "update", "--id", p.App.ClientID, "--is-fallback-public-client"}
+	if p.SwitchPrivatePublic {
+		appPublic := append(azCmd, "true")
+
+		tflog.Info(ctx, "Workaround : Make app public while getting token")
+		cmd := exec.Command(appPublic[0], appPublic[1:]...)
+		_, err := cmd.Output()
+
+		if err != nil {
+			tflog.Info(ctx, err.Error())
+		}
+
+		// Make it back to private whether we get the token or not
+		defer func() {
+			appPrivate := append(azCmd, "false")
+			tflog.Info(ctx, "Workaround : Make app back to private")
+			cmd := exec.Command(appPrivate[0], appPrivate[1:]...)
+			if _, err := cmd.Output(); err != nil {
+				tflog.Info(ctx, err.Error())
+			}
+		}()
+
+		wait := p.SwitchPrivatePublicWait
+		if wait == 0 {
+			wait = SWITCH_PRIVATE_PUBLIC_DEFAULT_WAIT
+		}
+		tflog.Info(ctx, fmt.Sprintf("Workaround : sleep %s to take effect", wait))
+		time.Sleep(wait)
+
+	}
+
+	// Now get token using public app
+	app, err := p.App.newPublicClient()
+
+	if err != nil {
+		return AccessToken{}, err
+	}
+	result, err := app.AcquireTokenByUsernamePassword(ctx, scopes, p.Username, p.Password)
+
+	if err != nil {
+		return AccessToken{}, err
+	}
+	return AccessToken{Token: result.AccessToken, ExpiresOn: result.ExpiresOn}, nil
+}
@@ -0,0 +1,45 @@
+package azdo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPasswordTokenProvider_SwitchBackOnError(t *testing.T) {
+	// A fake az CLI records the switches of the app registration
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho \"$@\" >> " + calls + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "az"), []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cloud, err := NewCloud(CLOUD_CUSTOM, Cloud{AuthorityHost: "https://127.0.0.1:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := &PasswordTokenProvider{
+		App:                     AppConfig{ClientID: "6145d7e0-7adf-4a48-b516-4f61cb047efd", Authority: "128c6ba1-f30f-4176-87d4-a93c61ae4ef0", Cloud: cloud},
+		Username:                "user@myorganization.com",
+		Password:                "usersuperpassword",
+		SwitchPrivatePublic:     true,
+		SwitchPrivatePublicWait: time.Millisecond,
+	}
+	if _, err := provider.GetToken(context.Background(), []string{cloud.AzureDevopsScope()}); err == nil {
+		t.Fatal("expected an error from an unreachable authority")
+	}
+
+	out, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "--is-fallback-public-client true") || !strings.HasSuffix(lines[1], "--is-fallback-public-client false") {
+		t.Errorf("expected the app to be switched public then back to private, got %q", lines)
+	}
+}
@@ -0,0 +1,82 @@
+package azdotest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+// auditPat records an event of the lifecycle of pat done by who.
+func (s *Server) auditPat(who identity, actionID, category string, pat *Pat) {
+	data, _ := json.Marshal(map[string]string{
+		"AuthorizationId": pat.AuthorizationID,
+		"DisplayName":     pat.DisplayName,
+		"Scopes":          pat.Scope,
+	})
+	s.auditLog = append(s.auditLog, azdo.AuditLogEntry{
+		ID:                      s.nextID("audit"),
+		CorrelationID:           pat.AuthorizationID,
+		ActionID:                actionID,
+		Area:                    "Token",
+		Category:                category,
+		Timestamp:               time.Now().UTC(),
+		ActorUserID:             who.ID,
+		ActorDisplayName:        who.DisplayName,
+		IPAddress:               "127.0.0.1",
+		ScopeDisplayName:        ORGANIZATION + " (Organization)",
+		Details:                 actionID + " " + pat.DisplayName,
+		Data:                    data,
+		AuthenticationMechanism: "AAD_Cookie",
+	})
+}
+
+// serveAuditLog implements the audit log query API, newest events first as Azure Devops does.
+func (s *Server) serveAuditLog(w http.ResponseWriter, r *http.Request, organization string) {
+	if organization != ORGANIZATION {
+		writeError(w, http.StatusNotFound, "TF400898: organization %s not found.", organization)
+		return
+	}
+	if r.URL.Query().Get("api-version") != azdo.AUDIT_API_VERSION {
+		writeError(w, http.StatusBadRequest, "VssVersionNotSupportedException: api-version %q is not supported.", r.URL.Query().Get("api-version"))
+		return
+	}
+	if _, ok := s.authenticateBearer(r, azdo.AZ_DEVOPS_RESOURCE_ID); !ok {
+		s.unauthorized(w)
+		return
+	}
+	startTime, err := time.Parse(time.RFC3339, r.URL.Query().Get("startTime"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid startTime: %v", err)
+		return
+	}
+	endTime := time.Now()
+	if value := r.URL.Query().Get("endTime"); value != "" {
+		if endTime, err = time.Parse(time.RFC3339, value); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid endTime: %v", err)
+			return
+		}
+	}
+
+	entries := []azdo.AuditLogEntry{}
+	for i := len(s.auditLog) - 1; i >= 0; i-- {
+		entry := s.auditLog[i]
+		if !entry.Timestamp.Before(startTime) && entry.Timestamp.Before(endTime) {
+			entries = append(entries, entry)
+		}
+	}
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("continuationToken"))
+	if start > len(entries) {
+		start = len(entries)
+	}
+	page := azdo.AuditLogQueryResult{DecoratedAuditLogEntries: entries[start:]}
+	if len(page.DecoratedAuditLogEntries) > AUDIT_PAGE_SIZE {
+		page.DecoratedAuditLogEntries = page.DecoratedAuditLogEntries[:AUDIT_PAGE_SIZE]
+		page.ContinuationToken = strconv.Itoa(start + AUDIT_PAGE_SIZE)
+		page.HasMore = true
+	}
+	writeJSON(w, http.StatusOK, page)
+}
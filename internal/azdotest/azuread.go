@@ -0,0 +1,229 @@
+package azdotest
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const ACCESS_TOKEN_LIFETIME time.Duration = time.Hour
+
+func (s *Server) serveOpenIDConfiguration(w http.ResponseWriter, tenant string) {
+	if tenant != TENANT_ID && tenant != "organizations" && tenant != "common" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_tenant",
+			"error_description": fmt.Sprintf("AADSTS90002: Tenant '%s' not found.", tenant),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"authorization_endpoint": s.URL + "/" + tenant + "/oauth2/v2.0/authorize",
+		"token_endpoint":         s.URL + "/" + tenant + "/oauth2/v2.0/token",
+		"issuer":                 s.URL + "/" + TENANT_ID + "/v2.0",
+	})
+}
+
+func (s *Server) serveUserRealm(w http.ResponseWriter, username string) {
+	domain := username[strings.LastIndex(username, "@")+1:]
+	writeJSON(w, http.StatusOK, map[string]string{
+		"account_type":        "Managed",
+		"domain_name":         domain,
+		"cloud_instance_name": "fake.azure",
+		"cloud_audience_urn":  "urn:federation:MicrosoftOnline",
+	})
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code string, format string, args ...any) {
+	writeJSON(w, status, map[string]string{
+		"error":             code,
+		"error_description": fmt.Sprintf(format, args...),
+	})
+}
+
+// serveToken implements the password and client credentials grants of the OAuth2 token endpoint.
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request, tenant string) {
+	if r.Method != http.MethodPost {
+		writeOAuthError(w, http.StatusMethodNotAllowed, "invalid_request", "AADSTS900561: The endpoint only accepts POST requests.")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "AADSTS900144: %v", err)
+		return
+	}
+	if tenant != TENANT_ID {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_tenant", "AADSTS90002: Tenant '%s' not found.", tenant)
+		return
+	}
+
+	clientID := r.PostForm.Get("client_id")
+	app, ok := s.apps[clientID]
+	if !ok {
+		writeOAuthError(w, http.StatusBadRequest, "unauthorized_client", "AADSTS700016: Application with identifier '%s' was not found in the directory.", clientID)
+		return
+	}
+
+	var who identity
+	switch r.PostForm.Get("grant_type") {
+	case "password":
+		if !app.IsFallbackPublicClient {
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "AADSTS7000218: The request body must contain the following parameter: 'client_assertion' or 'client_secret'.")
+			return
+		}
+		user, ok := s.users[strings.ToLower(r.PostForm.Get("username"))]
+		if !ok || user.Password != r.PostForm.Get("password") {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "AADSTS50126: Error validating credentials due to invalid username or password.")
+			return
+		}
+		who = identity{ID: user.ID, Descriptor: user.Descriptor, DisplayName: user.DisplayName}
+	case "client_credentials":
+		if err := app.authenticate(r.PostForm.Get("client_secret"), r.PostForm.Get("client_assertion")); err != nil {
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "%v", err)
+			return
+		}
+		who = identity{ID: app.ObjectID, Descriptor: "aadsp." + app.ObjectID, DisplayName: app.ClientID}
+	default:
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "AADSTS70003: grant_type %q is not supported by the fake.", r.PostForm.Get("grant_type"))
+		return
+	}
+
+	audience := scopesAudience(r.PostForm.Get("scope"))
+	token := s.nextID("fake-access-token")
+	s.tokens[token] = accessToken{identity: who, audience: audience, expiresOn: time.Now().Add(ACCESS_TOKEN_LIFETIME)}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"token_type":     "Bearer",
+		"access_token":   token,
+		"expires_in":     int(ACCESS_TOKEN_LIFETIME.Seconds()),
+		"ext_expires_in": int(ACCESS_TOKEN_LIFETIME.Seconds()),
+		"scope":          audience + "/.default",
+	})
+}
+
+// scopesAudience returns the resource of the '<resource>/.default' scope requested.
+func scopesAudience(scopes string) string {
+	for _, scope := range strings.Fields(scopes) {
+		if strings.HasSuffix(scope, "/.default") {
+			return strings.TrimSuffix(scope, "/.default")
+		}
+	}
+	return ""
+}
+
+// authenticate checks the client secret, or a client assertion either federated or signed by the app certificate.
+func (a *App) authenticate(secret string, assertion string) error {
+	switch {
+	case secret != "":
+		if a.ClientSecret == "" || secret != a.ClientSecret {
+			return fmt.Errorf("AADSTS7000215: Invalid client secret provided.")
+		}
+		return nil
+	case assertion != "":
+		if a.FederatedToken != "" && assertion == a.FederatedToken {
+			return nil
+		}
+		if a.Certificate == nil {
+			return fmt.Errorf("AADSTS700213: No matching federated identity record found for presented assertion.")
+		}
+		return a.verifyAssertion(assertion)
+	default:
+		return fmt.Errorf("AADSTS7000218: The request body must contain the following parameter: 'client_assertion' or 'client_secret'.")
+	}
+}
+
+// verifyAssertion checks an RS256 JWT is issued by the app and signed with the key of its certificate.
+func (a *App) verifyAssertion(assertion string) error {
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("AADSTS50027: JWT token is invalid or malformed.")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("AADSTS50027: JWT token is invalid or malformed.")
+	}
+	claims := struct {
+		Issuer  string `json:"iss"`
+		Subject string `json:"sub"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return fmt.Errorf("AADSTS50027: JWT token is invalid or malformed.")
+	}
+	if claims.Issuer != a.ClientID || claims.Subject != a.ClientID {
+		return fmt.Errorf("AADSTS700021: Client assertion application identifier doesn't match 'client_id' parameter.")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("AADSTS50027: JWT token is invalid or malformed.")
+	}
+	publicKey, ok := a.Certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("AADSTS700027: Client assertion contains an invalid signature.")
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return fmt.Errorf("AADSTS700027: Client assertion contains an invalid signature.")
+	}
+	return nil
+}
+
+// authenticateBearer returns who the bearer access token of the request was issued to, for audience.
+func (s *Server) authenticateBearer(r *http.Request, audience string) (identity, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return identity{}, false
+	}
+	issued, ok := s.tokens[token]
+	if !ok || issued.audience != audience || time.Now().After(issued.expiresOn) {
+		return identity{}, false
+	}
+	return issued.identity, true
+}
+
+// serveApplication fakes the Graph application API, enough to switch apps between public and confidential.
+func (s *Server) serveApplication(w http.ResponseWriter, r *http.Request, objectID string) {
+	if _, ok := s.authenticateBearer(r, s.URL); !ok {
+		writeError(w, http.StatusUnauthorized, "Access token is empty or invalid.")
+		return
+	}
+
+	var app *App
+	for _, candidate := range s.apps {
+		if candidate.ObjectID == objectID {
+			app = candidate
+		}
+	}
+	if app == nil {
+		writeError(w, http.StatusNotFound, "Resource '%s' does not exist or one of its queried reference-property objects are not present.", objectID)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPatch:
+		patch := struct {
+			IsFallbackPublicClient *bool `json:"isFallbackPublicClient"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body: %v", err)
+			return
+		}
+		if patch.IsFallbackPublicClient != nil {
+			app.IsFallbackPublicClient = *patch.IsFallbackPublicClient
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method %s is not allowed.", r.Method)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":                     app.ObjectID,
+		"appId":                  app.ClientID,
+		"isFallbackPublicClient": app.IsFallbackPublicClient,
+	})
+}
@@ -0,0 +1,241 @@
+package azdotest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+// authenticateDevops returns who the request is authenticated as, either with
+// an Azure Devops access token or with an active PAT as basic auth password.
+func (s *Server) authenticateDevops(r *http.Request) (identity, bool) {
+	if who, ok := s.authenticateBearer(r, azdo.AZ_DEVOPS_RESOURCE_ID); ok {
+		return who, true
+	}
+	_, password, ok := r.BasicAuth()
+	if !ok {
+		return identity{}, false
+	}
+	for _, pat := range s.pats {
+		if pat.Token == password && pat.Active() && !time.Now().Before(pat.UsableFrom) {
+			return s.identity(pat.OwnerID), true
+		}
+	}
+	return identity{}, false
+}
+
+func (s *Server) identity(id string) identity {
+	for _, user := range s.users {
+		if user.ID == id {
+			return identity{ID: user.ID, Descriptor: user.Descriptor, DisplayName: user.DisplayName}
+		}
+	}
+	for _, app := range s.apps {
+		if app.ObjectID == id {
+			return identity{ID: app.ObjectID, Descriptor: "aadsp." + app.ObjectID, DisplayName: app.ClientID}
+		}
+	}
+	return identity{ID: id}
+}
+
+func (s *Server) unauthorized(w http.ResponseWriter) {
+	w.Header().Set("X-VSS-ResourceTenant", TENANT_ID)
+	w.Header().Set("WWW-Authenticate", `Bearer authorization_uri=`+s.URL+"/"+TENANT_ID)
+	writeError(w, http.StatusUnauthorized, "TF400813: The user is not authorized to access this resource.")
+}
+
+func (s *Server) serveConnectionData(w http.ResponseWriter, r *http.Request, organization string) {
+	if organization != ORGANIZATION {
+		writeError(w, http.StatusNotFound, "TF400898: organization %s not found.", organization)
+		return
+	}
+	who, ok := s.authenticateDevops(r)
+	if !ok {
+		s.unauthorized(w)
+		return
+	}
+	user := map[string]string{
+		"id":                  who.ID,
+		"descriptor":          who.Descriptor,
+		"subjectDescriptor":   who.Descriptor,
+		"providerDisplayName": who.DisplayName,
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"authenticatedUser": user,
+		"authorizedUser":    user,
+		"instanceId":        ORGANIZATION_ID,
+		"deploymentType":    "hosted",
+	})
+}
+
+// scopedAPIs maps the organization APIs PATs are checked against to the scope area they require.
+var scopedAPIs = map[string]string{
+	"build/definitions": "vso.build",
+	"git/repositories":  "vso.code",
+	"projects":          "vso.project",
+}
+
+// serveScopedAPI lists nothing, to callers whose PAT has a scope of area or full access.
+func (s *Server) serveScopedAPI(w http.ResponseWriter, r *http.Request, organization, area string) {
+	if organization != ORGANIZATION {
+		writeError(w, http.StatusNotFound, "TF400898: organization %s not found.", organization)
+		return
+	}
+	who, ok := s.authenticateDevops(r)
+	if !ok {
+		s.unauthorized(w)
+		return
+	}
+	if _, password, isPat := r.BasicAuth(); isPat {
+		allowed := false
+		for _, pat := range s.pats {
+			if pat.Token != password {
+				continue
+			}
+			for _, scope := range strings.Fields(pat.Scope) {
+				scopeArea, _, _ := strings.Cut(scope, "_")
+				allowed = allowed || scope == "app_token" || scopeArea == area
+			}
+		}
+		if !allowed {
+			writeError(w, http.StatusUnauthorized, "TF400813: The user '%s' is not authorized to access this resource.", who.ID)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"count": 0, "value": []any{}})
+}
+
+// servePats implements the PAT lifecycle API, each identity only sees its own PATs.
+func (s *Server) servePats(w http.ResponseWriter, r *http.Request, organization string) {
+	if organization != ORGANIZATION {
+		writeError(w, http.StatusNotFound, "TF400898: organization %s not found.", organization)
+		return
+	}
+	if r.URL.Query().Get("api-version") != azdo.PAT_API_VERSION {
+		writeError(w, http.StatusBadRequest, "VssVersionNotSupportedException: api-version %q is not supported.", r.URL.Query().Get("api-version"))
+		return
+	}
+	who, ok := s.authenticateBearer(r, azdo.AZ_DEVOPS_RESOURCE_ID)
+	if !ok {
+		s.unauthorized(w)
+		return
+	}
+
+	authorizationID := r.URL.Query().Get("authorizationId")
+	switch {
+	case r.Method == http.MethodPost:
+		req := azdo.CreatePatRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body: %v", err)
+			return
+		}
+		if req.DisplayName == "" || req.Scope == "" {
+			writeJSON(w, http.StatusOK, azdo.PatTokenResult{PatTokenError: "invalidDisplayName"})
+			return
+		}
+		if err := s.patPolicyViolation(req); err != "" {
+			writeJSON(w, http.StatusOK, azdo.PatTokenResult{PatTokenError: err})
+			return
+		}
+		now := time.Now().UTC().Truncate(time.Second)
+		pat := &Pat{
+			PatToken: azdo.PatToken{
+				DisplayName:     req.DisplayName,
+				ValidTo:         req.ValidTo.UTC().Truncate(time.Second),
+				Scope:           req.Scope,
+				TargetAccounts:  []string{ORGANIZATION_ID},
+				ValidFrom:       now,
+				AuthorizationID: s.nextID("authorization"),
+				Token:           s.nextID("fake-pat"),
+			},
+			OwnerID:    who.ID,
+			UsableFrom: time.Now().Add(s.patPropagationDelay),
+		}
+		s.pats = append(s.pats, pat)
+		s.auditPat(who, "Token.PatCreateEvent", "create", pat)
+		writeJSON(w, http.StatusOK, azdo.PatTokenResult{PatToken: pat.PatToken, PatTokenError: "none"})
+	case r.Method == http.MethodGet && authorizationID == "":
+		s.listPats(w, r, who)
+	case r.Method == http.MethodGet:
+		pat := s.ownedPat(who, authorizationID)
+		if pat == nil {
+			writeError(w, http.StatusNotFound, "Token not found.")
+			return
+		}
+		writeJSON(w, http.StatusOK, azdo.PatTokenResult{PatToken: pat.withoutSecret(), PatTokenError: "none"})
+	case r.Method == http.MethodPut:
+		req := azdo.UpdatePatRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body: %v", err)
+			return
+		}
+		pat := s.ownedPat(who, req.AuthorizationID)
+		if pat == nil {
+			writeError(w, http.StatusNotFound, "Token not found.")
+			return
+		}
+		pat.DisplayName, pat.Scope, pat.ValidTo = req.DisplayName, req.Scope, req.ValidTo.UTC().Truncate(time.Second)
+		writeJSON(w, http.StatusOK, azdo.PatTokenResult{PatToken: pat.withoutSecret(), PatTokenError: "none"})
+	case r.Method == http.MethodDelete:
+		pat := s.ownedPat(who, authorizationID)
+		if pat == nil {
+			writeError(w, http.StatusNotFound, "Token not found.")
+			return
+		}
+		pat.Revoked = true
+		s.auditPat(who, "Token.PatRevokeEvent", "remove", pat)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method %s is not allowed.", r.Method)
+	}
+}
+
+// ownedPat returns the PAT of who, nil when unknown, owned by another identity or already revoked.
+func (s *Server) ownedPat(who identity, authorizationID string) *Pat {
+	for _, pat := range s.pats {
+		if pat.AuthorizationID == authorizationID && pat.OwnerID == who.ID && !pat.Revoked {
+			return pat
+		}
+	}
+	return nil
+}
+
+func (p *Pat) withoutSecret() azdo.PatToken {
+	patToken := p.PatToken
+	patToken.Token = ""
+	return patToken
+}
+
+func (s *Server) listPats(w http.ResponseWriter, r *http.Request, who identity) {
+	filter := r.URL.Query().Get("displayFilterOption")
+	if filter == "" {
+		filter = "active"
+	}
+
+	patTokens := []azdo.PatToken{}
+	for _, pat := range s.pats {
+		if pat.OwnerID != who.ID {
+			continue
+		}
+		expired := !pat.Revoked && !time.Now().Before(pat.ValidTo)
+		if (filter == "active" && !pat.Active()) || (filter == "revoked" && !pat.Revoked) || (filter == "expired" && !expired) {
+			continue
+		}
+		patTokens = append(patTokens, pat.withoutSecret())
+	}
+
+	start, _ := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("continuationToken")))
+	if start > len(patTokens) {
+		start = len(patTokens)
+	}
+	page := azdo.PagedPatTokens{PatTokens: patTokens[start:]}
+	if len(page.PatTokens) > PAT_PAGE_SIZE {
+		page.PatTokens = page.PatTokens[:PAT_PAGE_SIZE]
+		page.ContinuationToken = strconv.Itoa(start + PAT_PAGE_SIZE)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
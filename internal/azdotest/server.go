@@ -0,0 +1,305 @@
+// Package azdotest provides an in-process fake of the AzureAD and Azure Devops
+// APIs used by the provider, so that acceptance tests run without network.
+package azdotest
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+const (
+	TENANT_ID       string = "128c6ba1-f30f-4176-87d4-a93c61ae4ef0"
+	ORGANIZATION    string = "myorganization"
+	ORGANIZATION_ID string = "a6d3c5f4-2b1e-4f0a-9c8d-7e6f5a4b3c2d"
+	// PAT_PAGE_SIZE is the number of PATs listed per page, small enough for tests to go through continuation tokens
+	PAT_PAGE_SIZE int = 2
+	// AUDIT_PAGE_SIZE is the number of audit events per page, whatever the batch size asked
+	AUDIT_PAGE_SIZE int = 2
+	// VSSPS_PATH_PREFIX serves the vssps APIs apart from the organization ones, as on a distinct host,
+	// e.g. <URL>/vssps/<organization>/_apis/tokens/pats
+	VSSPS_PATH_PREFIX string = "vssps"
+)
+
+// App is an app registration of the fake tenant.
+type App struct {
+	ClientID               string
+	ObjectID               string
+	IsFallbackPublicClient bool
+	ClientSecret           string
+	// Certificate verifies the client assertions signed by the app
+	Certificate *x509.Certificate
+	// FederatedToken is the CI token accepted as client assertion through workload identity federation
+	FederatedToken string
+}
+
+// User is an Azure Devops user of the fake tenant.
+type User struct {
+	Username    string
+	Password    string
+	DisplayName string
+	ID          string
+	Descriptor  string
+}
+
+// identity is who an access token or a PAT authenticates, a user or an app.
+type identity struct {
+	ID          string
+	Descriptor  string
+	DisplayName string
+}
+
+type accessToken struct {
+	identity  identity
+	audience  string
+	expiresOn time.Time
+}
+
+// Pat is a PAT held by the fake PAT API along with its owner.
+type Pat struct {
+	azdo.PatToken
+	OwnerID string
+	Revoked bool
+	// UsableFrom is when the PAT starts authenticating, as PATs take a while to propagate
+	UsableFrom time.Time
+}
+
+// Server fakes the authority host, Microsoft Graph, Azure Devops and its PAT API
+// on a single TLS server, see Cloud for the matching provider environment.
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	apps   map[string]*App
+	users  map[string]*User
+	tokens map[string]accessToken
+	pats   []*Pat
+	serial int
+	// patPropagationDelay is how long new PATs are rejected for
+	patPropagationDelay time.Duration
+	// patPolicy is enforced on PAT creation and read through the token admin API
+	patPolicy azdo.PatPolicy
+	// patPolicyUnavailable makes the token admin API answer 404 to policy reads, as organizations without the preview do
+	patPolicyUnavailable bool
+	// auditLog records the PAT lifecycle events, oldest first
+	auditLog []azdo.AuditLogEntry
+	// tokenAdmins are the IDs of the identities allowed to manage the PATs of others through the token admin API
+	tokenAdmins map[string]bool
+}
+
+// NewServer starts a fake holding no app registration nor user, call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		apps:   map[string]*App{},
+		users:  map[string]*User{},
+		tokens: map[string]accessToken{},
+
+		tokenAdmins: map[string]bool{},
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Cloud returns the custom cloud whose endpoints all point at the fake.
+func (s *Server) Cloud() azdo.Cloud {
+	return azdo.Cloud{
+		Name:                  azdo.CLOUD_CUSTOM,
+		AuthorityHost:         s.URL,
+		GraphEndpoint:         s.URL,
+		AzureDevopsResourceID: azdo.AZ_DEVOPS_RESOURCE_ID,
+		AzureDevopsURL:        s.URL,
+		AzureDevopsVsspsURL:   s.URL,
+		AzureDevopsAuditURL:   s.URL,
+	}
+}
+
+func (s *Server) AddApp(app App) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apps[app.ClientID] = &app
+}
+
+func (s *Server) AddUser(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[strings.ToLower(user.Username)] = &user
+}
+
+// App returns the current registration of an app, as changed through Graph.
+func (s *Server) App(clientID string) (App, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	app, ok := s.apps[clientID]
+	if !ok {
+		return App{}, false
+	}
+	return *app, true
+}
+
+// SetPatPropagationDelay makes the PATs created from now on rejected for delay.
+func (s *Server) SetPatPropagationDelay(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.patPropagationDelay = delay
+}
+
+// SetPatPolicy makes the organization enforce policy on the PATs created from now on.
+func (s *Server) SetPatPolicy(policy azdo.PatPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.patPolicy = policy
+}
+
+// SetPatPolicyUnavailable makes the token admin API stop serving the PAT policy, the policy is still enforced.
+func (s *Server) SetPatPolicyUnavailable(unavailable bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.patPolicyUnavailable = unavailable
+}
+
+// SetTokenAdmins makes the users or apps ids organization administrators, allowed to list and revoke the PATs of anyone.
+func (s *Server) SetTokenAdmins(ids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenAdmins = map[string]bool{}
+	for _, id := range ids {
+		s.tokenAdmins[id] = true
+	}
+}
+
+// AddAuditEvent records an event in the audit log, as if done outside of terraform.
+func (s *Server) AddAuditEvent(entry azdo.AuditLogEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry.ID == "" {
+		entry.ID = s.nextID("audit")
+	}
+	s.auditLog = append(s.auditLog, entry)
+}
+
+// TokenAudience returns the audience an access token was issued for, false when unknown or expired.
+func (s *Server) TokenAudience(token string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issued, ok := s.tokens[token]
+	if !ok || time.Now().After(issued.expiresOn) {
+		return "", false
+	}
+	return issued.audience, true
+}
+
+// Pats returns every PAT created on the fake, revoked ones included.
+func (s *Server) Pats() []Pat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pats := []Pat{}
+	for _, pat := range s.pats {
+		pats = append(pats, *pat)
+	}
+	return pats
+}
+
+// ActivePats returns the PATs neither revoked nor expired.
+func (s *Server) ActivePats() []Pat {
+	pats := []Pat{}
+	for _, pat := range s.Pats() {
+		if pat.Active() {
+			pats = append(pats, pat)
+		}
+	}
+	return pats
+}
+
+// AddPat adds an active PAT of the user or app ownerID, as if created outside of terraform.
+func (s *Server) AddPat(ownerID, displayName, scope string) Pat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC().Truncate(time.Second)
+	pat := &Pat{
+		PatToken: azdo.PatToken{
+			DisplayName:     displayName,
+			ValidTo:         now.AddDate(1, 0, 0),
+			Scope:           scope,
+			TargetAccounts:  []string{ORGANIZATION_ID},
+			ValidFrom:       now,
+			AuthorizationID: s.nextID("authorization"),
+			Token:           s.nextID("fake-pat"),
+		},
+		OwnerID: ownerID,
+	}
+	s.pats = append(s.pats, pat)
+	return *pat
+}
+
+// RevokePat revokes a PAT as if done by the user outside of terraform.
+func (s *Server) RevokePat(authorizationID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pat := range s.pats {
+		if pat.AuthorizationID == authorizationID && !pat.Revoked {
+			pat.Revoked = true
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pat) Active() bool {
+	return !p.Revoked && time.Now().Before(p.ValidTo)
+}
+
+func (s *Server) nextID(prefix string) string {
+	s.serial++
+	return fmt.Sprintf("%s-%04d", prefix, s.serial)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	vssps := len(segments) > 1 && segments[0] == VSSPS_PATH_PREFIX
+	if vssps {
+		segments = segments[1:]
+	}
+	switch {
+	case len(segments) == 4 && segments[1] == "v2.0" && segments[2] == ".well-known" && segments[3] == "openid-configuration":
+		s.serveOpenIDConfiguration(w, segments[0])
+	case len(segments) == 3 && strings.EqualFold(segments[0], "common") && strings.EqualFold(segments[1], "UserRealm"):
+		s.serveUserRealm(w, segments[2])
+	case len(segments) == 4 && segments[1] == "oauth2" && segments[2] == "v2.0" && segments[3] == "token":
+		s.serveToken(w, r, segments[0])
+	case len(segments) == 3 && segments[0] == "v1.0" && segments[1] == "applications":
+		s.serveApplication(w, r, segments[2])
+	case len(segments) == 3 && segments[1] == "_apis" && segments[2] == "connectionData":
+		s.serveConnectionData(w, r, segments[0])
+	case len(segments) == 4 && segments[1] == "_apis" && segments[2] == "tokens" && segments[3] == "pats":
+		s.servePats(w, r, segments[0])
+	case len(segments) >= 4 && segments[1] == "_apis" && segments[2] == "tokenadmin":
+		s.serveTokenAdmin(w, r, segments[0], strings.Join(segments[3:], "/"))
+	case len(segments) == 4 && segments[1] == "_apis" && segments[2] == "audit" && segments[3] == "auditlog":
+		s.serveAuditLog(w, r, segments[0])
+	// The organization APIs are not served by vssps
+	case !vssps && len(segments) >= 3 && segments[1] == "_apis" && scopedAPIs[strings.Join(segments[2:], "/")] != "":
+		s.serveScopedAPI(w, r, segments[0], scopedAPIs[strings.Join(segments[2:], "/")])
+	default:
+		writeError(w, http.StatusNotFound, "no fake for %s %s", r.Method, r.URL.Path)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, map[string]string{"message": fmt.Sprintf(format, args...)})
+}
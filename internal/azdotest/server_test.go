@@ -0,0 +1,218 @@
+package azdotest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+const (
+	testClientID = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+	testUsername = "user@myorganization.com"
+	testPassword = "usersuperpassword"
+)
+
+func newTestServer(t *testing.T, public bool) *Server {
+	t.Helper()
+
+	server := NewServer()
+	t.Cleanup(server.Close)
+	server.AddApp(App{ClientID: testClientID, ObjectID: "app-object-id", IsFallbackPublicClient: public, ClientSecret: "appsecret"})
+	server.AddUser(User{Username: testUsername, Password: testPassword, DisplayName: "User", ID: "user-id", Descriptor: "aad.user"})
+	return server
+}
+
+func testApp(server *Server) azdo.AppConfig {
+	return azdo.AppConfig{ClientID: testClientID, Authority: TENANT_ID, Cloud: server.Cloud(), HTTPClient: server.Client()}
+}
+
+func TestServer_PasswordPatLifecycle(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, true)
+	tokens := &azdo.PasswordTokenProvider{App: testApp(server), Username: testUsername, Password: testPassword}
+	client := azdo.NewPatClient(server.Client(), server.Cloud().PatEndpoint(ORGANIZATION), tokens, server.Cloud().AzureDevopsScope())
+
+	for _, name := range []string{"first", "second", "third"} {
+		if _, err := client.Create(ctx, azdo.CreatePatRequest{DisplayName: name, Scope: "vso.code", ValidTo: time.Now().AddDate(0, 1, 0)}); err != nil {
+			t.Fatalf("unexpected error creating %s: %v", name, err)
+		}
+	}
+	patTokens, err := client.List(ctx, azdo.ListPatsOptions{})
+	if err != nil || len(patTokens) != 3 {
+		t.Fatalf("expected 3 PATs listed through continuation tokens, got %v, %v", patTokens, err)
+	}
+
+	if err := client.Revoke(ctx, patTokens[0].AuthorizationID); err != nil {
+		t.Fatalf("unexpected error revoking: %v", err)
+	}
+	_, err = client.Get(ctx, patTokens[0].AuthorizationID)
+	apiErr := &azdo.APIError{}
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 on a revoked PAT, got %v", err)
+	}
+	if got := len(server.ActivePats()); got != 2 {
+		t.Errorf("expected 2 active PATs, got %d", got)
+	}
+	if owner := server.Pats()[1].OwnerID; owner != "user-id" {
+		t.Errorf("expected PATs owned by the user, got %s", owner)
+	}
+}
+
+func TestServer_PasswordRequiresPublicApp(t *testing.T) {
+	server := newTestServer(t, false)
+	tokens := &azdo.PasswordTokenProvider{App: testApp(server), Username: testUsername, Password: testPassword}
+
+	if _, err := tokens.GetToken(context.Background(), []string{server.Cloud().AzureDevopsScope()}); err == nil {
+		t.Error("expected the password grant to fail on a confidential app")
+	}
+}
+
+func TestServer_ClientSecretAndGraph(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, false)
+	tokens, err := azdo.NewClientSecretTokenProvider(testApp(server), "appsecret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := tokens.GetToken(ctx, []string{server.Cloud().GraphScope()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPatch, server.Cloud().ApplicationsEndpoint()+"/app-object-id", strings.NewReader(`{"isFallbackPublicClient":true}`))
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", res.StatusCode)
+	}
+	if app, _ := server.App(testClientID); !app.IsFallbackPublicClient {
+		t.Error("expected the app to be switched public")
+	}
+
+	if _, err := azdo.NewPatClient(server.Client(), server.Cloud().PatEndpoint(ORGANIZATION), staticToken(token.Token), server.Cloud().AzureDevopsScope()).List(ctx, azdo.ListPatsOptions{}); err == nil {
+		t.Error("expected a Graph token to be rejected by the PAT API")
+	}
+}
+
+func TestServer_DiscoverOrganizationTenant(t *testing.T) {
+	server := newTestServer(t, true)
+
+	tenant, err := azdo.DiscoverOrganizationTenant(context.Background(), server.Client(), server.Cloud().OrganizationURL(ORGANIZATION))
+	if err != nil || tenant != TENANT_ID {
+		t.Errorf("expected tenant %s, got %s, %v", TENANT_ID, tenant, err)
+	}
+}
+
+type staticToken string
+
+func (t staticToken) GetToken(_ context.Context, _ []string) (azdo.AccessToken, error) {
+	return azdo.AccessToken{Token: string(t), ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestServer_PatPolicy(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, true)
+	server.SetPatPolicy(azdo.PatPolicy{MaxLifespanDays: 30, RestrictFullScope: true})
+	tokens := &azdo.PasswordTokenProvider{App: testApp(server), Username: testUsername, Password: testPassword}
+	patEndpoint := server.Cloud().PatEndpoint(ORGANIZATION)
+	client := azdo.NewPatClient(server.Client(), patEndpoint, tokens, server.Cloud().AzureDevopsScope())
+
+	policy, err := azdo.NewTokenAdminClient(server.Client(), azdo.TokenAdminEndpoint(patEndpoint), tokens, server.Cloud().AzureDevopsScope()).GetPatPolicy(ctx)
+	if err != nil || policy.MaxLifespanDays != 30 || !policy.RestrictFullScope || policy.RestrictGlobal {
+		t.Fatalf("unexpected policy %+v, %v", policy, err)
+	}
+
+	for _, req := range []azdo.CreatePatRequest{
+		{DisplayName: "full", Scope: "app_token", ValidTo: time.Now().AddDate(0, 0, 7)},
+		{DisplayName: "long", Scope: "vso.code", ValidTo: time.Now().AddDate(0, 0, 60)},
+	} {
+		if _, err := client.Create(ctx, req); err == nil || !strings.Contains(err.Error(), "PolicyViolation") {
+			t.Errorf("expected PAT %s to violate the policy, got %v", req.DisplayName, err)
+		}
+	}
+	if _, err := client.Create(ctx, azdo.CreatePatRequest{DisplayName: "allowed", Scope: "vso.code", ValidTo: time.Now().AddDate(0, 0, 30)}); err != nil {
+		t.Errorf("unexpected error creating a PAT within the policy: %v", err)
+	}
+}
+
+func TestServer_TokenAdminRevocation(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, true)
+	tokens := &azdo.PasswordTokenProvider{App: testApp(server), Username: testUsername, Password: testPassword}
+	admin := azdo.NewTokenAdminClient(server.Client(), azdo.TokenAdminEndpoint(server.Cloud().PatEndpoint(ORGANIZATION)), tokens, server.Cloud().AzureDevopsScope())
+
+	var created []Pat
+	for _, name := range []string{"first", "second", "third"} {
+		created = append(created, server.AddPat("user-id", name, "vso.code"))
+	}
+	other := server.AddPat("app-object-id", "other", "vso.build")
+
+	var apiErr *azdo.APIError
+	if _, err := admin.ListUserPats(ctx, "aad.user"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected non admins to be forbidden, got %v", err)
+	}
+
+	server.SetTokenAdmins("user-id")
+	sessionTokens, err := admin.ListUserPats(ctx, "aad.user")
+	if err != nil || len(sessionTokens) != 3 {
+		t.Fatalf("expected the 3 PATs of the user across pages, got %+v, %v", sessionTokens, err)
+	}
+	if err := admin.RevokeAuthorizations(ctx, []string{created[0].AuthorizationID, other.AuthorizationID, "unknown"}); err != nil {
+		t.Fatalf("unexpected error revoking: %v", err)
+	}
+	if active := server.ActivePats(); len(active) != 2 || active[0].AuthorizationID != created[1].AuthorizationID {
+		t.Errorf("expected the PATs of both owners revoked, got %+v", active)
+	}
+	sessionTokens, err = admin.ListUserPats(ctx, "aad.user")
+	if err != nil || sessionTokens[0].IsValid || !sessionTokens[1].IsValid {
+		t.Errorf("expected the revoked PAT to be listed as invalid, got %+v, %v", sessionTokens, err)
+	}
+}
+
+func TestServer_AuditLog(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, true)
+	tokens := &azdo.PasswordTokenProvider{App: testApp(server), Username: testUsername, Password: testPassword}
+	client := azdo.NewPatClient(server.Client(), server.Cloud().PatEndpoint(ORGANIZATION), tokens, server.Cloud().AzureDevopsScope())
+
+	start := time.Now().Add(-time.Minute)
+	server.AddAuditEvent(azdo.AuditLogEntry{ActionID: "Git.RepositoryCreated", Area: "Git", Timestamp: time.Now()})
+	for _, name := range []string{"first", "second"} {
+		patToken, err := client.Create(ctx, azdo.CreatePatRequest{DisplayName: name, Scope: "vso.code", ValidTo: time.Now().AddDate(0, 1, 0)})
+		if err != nil {
+			t.Fatalf("unexpected error creating %s: %v", name, err)
+		}
+		if err := client.Revoke(ctx, patToken.AuthorizationID); err != nil {
+			t.Fatalf("unexpected error revoking %s: %v", name, err)
+		}
+	}
+
+	entries, err := azdo.QueryAuditLog(ctx, server.Client(), server.Cloud().AuditLogEndpoint(ORGANIZATION), tokens, server.Cloud().AzureDevopsScope(), azdo.AuditLogOptions{
+		StartTime: start,
+		EndTime:   time.Now().Add(time.Minute),
+		Actions:   []string{"Token.*"},
+	})
+	if err != nil || len(entries) != 4 {
+		t.Fatalf("expected 4 token events listed through continuation tokens, got %v, %v", entries, err)
+	}
+	if entries[0].ActionID != "Token.PatRevokeEvent" || entries[0].ActorUserID != "user-id" || !strings.Contains(string(entries[0].Data), `"DisplayName":"second"`) {
+		t.Errorf("expected the latest revocation first, got %+v", entries[0])
+	}
+
+	entries, err = azdo.QueryAuditLog(ctx, server.Client(), server.Cloud().AuditLogEndpoint(ORGANIZATION), tokens, server.Cloud().AzureDevopsScope(), azdo.AuditLogOptions{
+		StartTime:  start,
+		MaxEntries: 3,
+	})
+	if err != nil || len(entries) != 3 {
+		t.Errorf("expected the query to stop after 3 events, got %v, %v", entries, err)
+	}
+}
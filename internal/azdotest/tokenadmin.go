@@ -0,0 +1,121 @@
+package azdotest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+// serveTokenAdmin implements the token admin API, its policies are readable by any identity of the organization
+// while only token admins list and revoke PATs.
+func (s *Server) serveTokenAdmin(w http.ResponseWriter, r *http.Request, organization, resource string) {
+	if organization != ORGANIZATION {
+		writeError(w, http.StatusNotFound, "TF400898: organization %s not found.", organization)
+		return
+	}
+	if r.URL.Query().Get("api-version") != azdo.TOKEN_ADMIN_API_VERSION {
+		writeError(w, http.StatusBadRequest, "VssVersionNotSupportedException: api-version %q is not supported.", r.URL.Query().Get("api-version"))
+		return
+	}
+	who, ok := s.authenticateBearer(r, azdo.AZ_DEVOPS_RESOURCE_ID)
+	if !ok {
+		s.unauthorized(w)
+		return
+	}
+	if resource != "policies" && !s.tokenAdmins[who.ID] {
+		writeError(w, http.StatusForbidden, "Access Denied: %s needs the following permission(s) on the resource Tokens to perform this action: Revoke", who.DisplayName)
+		return
+	}
+
+	descriptor, isPats := strings.CutPrefix(resource, "personalaccesstokens/")
+	switch {
+	case resource == "policies" && r.Method == http.MethodGet && s.patPolicyUnavailable:
+		writeError(w, http.StatusNotFound, "The controller for path '%s' was not found or does not implement IController.", r.URL.Path)
+	case resource == "policies" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.patPolicy)
+	case isPats && r.Method == http.MethodGet:
+		s.listUserPats(w, r, descriptor)
+	case resource == "revocations" && r.Method == http.MethodPost:
+		revocations := []azdo.TokenRevocation{}
+		if err := json.NewDecoder(r.Body).Decode(&revocations); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body: %v", err)
+			return
+		}
+		// Unknown or already revoked authorizations are ignored, as Azure Devops does
+		for _, revocation := range revocations {
+			for _, pat := range s.pats {
+				if pat.AuthorizationID == revocation.AuthorizationID && !pat.Revoked {
+					pat.Revoked = true
+					s.auditPat(who, "Token.PatRevokeEvent", "remove", pat)
+				}
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeError(w, http.StatusNotFound, "no fake for %s %s", r.Method, r.URL.Path)
+	}
+}
+
+// listUserPats lists every PAT of the user or app whose subject descriptor is descriptor, invalid ones included.
+func (s *Server) listUserPats(w http.ResponseWriter, r *http.Request, descriptor string) {
+	ownerID := ""
+	for _, user := range s.users {
+		if user.Descriptor == descriptor {
+			ownerID = user.ID
+		}
+	}
+	for _, app := range s.apps {
+		if "aadsp."+app.ObjectID == descriptor {
+			ownerID = app.ObjectID
+		}
+	}
+	if ownerID == "" {
+		writeError(w, http.StatusNotFound, "VS800075: The identity with descriptor %s could not be found.", descriptor)
+		return
+	}
+
+	tokens := []azdo.SessionToken{}
+	for _, pat := range s.pats {
+		if pat.OwnerID != ownerID {
+			continue
+		}
+		tokens = append(tokens, azdo.SessionToken{
+			AuthorizationID: pat.AuthorizationID,
+			DisplayName:     pat.DisplayName,
+			Scope:           pat.Scope,
+			ValidFrom:       pat.ValidFrom,
+			ValidTo:         pat.ValidTo,
+			IsValid:         pat.Active(),
+		})
+	}
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("continuationToken"))
+	if start > len(tokens) {
+		start = len(tokens)
+	}
+	page := azdo.PagedSessionTokens{Value: tokens[start:]}
+	if len(page.Value) > PAT_PAGE_SIZE {
+		page.Value = page.Value[:PAT_PAGE_SIZE]
+		page.ContinuationToken = strconv.Itoa(start + PAT_PAGE_SIZE)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// patPolicyViolation returns the PAT API error of a creation the organization policy forbids, "" when allowed.
+func (s *Server) patPolicyViolation(req azdo.CreatePatRequest) string {
+	if s.patPolicy.CheckScopes(req.Scope) != nil {
+		return azdo.PAT_ERROR_FULL_SCOPE_POLICY_VIOLATION
+	}
+	if req.AllOrgs && s.patPolicy.RestrictGlobal {
+		return azdo.PAT_ERROR_GLOBAL_POLICY_VIOLATION
+	}
+	// Allow for the time the request took to arrive
+	if maxValidTo, ok := s.patPolicy.MaxValidTo(time.Now().Add(time.Minute)); ok && req.ValidTo.After(maxValidTo) {
+		return azdo.PAT_ERROR_LIFESPAN_POLICY_VIOLATION
+	}
+	return ""
+}
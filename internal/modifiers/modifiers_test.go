@@ -0,0 +1,78 @@
+package modifiers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var testSchema = schema.Schema{
+	Attributes: map[string]schema.Attribute{
+		"value": schema.StringAttribute{Computed: true},
+		"owner": schema.StringAttribute{Optional: true},
+	},
+}
+
+// testData returns the raw plan or state of testSchema, null when attributes is nil.
+func testData(attributes map[string]*string) tftypes.Value {
+	objectType := testSchema.Type().TerraformType(context.Background())
+	if attributes == nil {
+		return tftypes.NewValue(objectType, nil)
+	}
+	values := map[string]tftypes.Value{}
+	for name := range testSchema.Attributes {
+		var value interface{}
+		if v, ok := attributes[name]; ok && v != nil {
+			value = *v
+		}
+		values[name] = tftypes.NewValue(tftypes.String, value)
+	}
+	return tftypes.NewValue(objectType, values)
+}
+
+// planModifyString runs m on the 'value' attribute of the state and plan, computed as it is not configured.
+func planModifyString(t *testing.T, m planmodifier.String, state map[string]*string, plan map[string]*string) *planmodifier.StringResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	req := planmodifier.StringRequest{
+		Path:  path.Root("value"),
+		State: tfsdk.State{Schema: testSchema, Raw: testData(state)},
+		Plan:  tfsdk.Plan{Schema: testSchema, Raw: testData(plan)},
+	}
+	config := map[string]*string{}
+	for name, value := range plan {
+		if name != "value" {
+			config[name] = value
+		}
+	}
+	if plan == nil {
+		config = nil
+	}
+	req.Config = tfsdk.Config{Schema: testSchema, Raw: testData(config)}
+	req.State.GetAttribute(ctx, req.Path, &req.StateValue)
+	req.Plan.GetAttribute(ctx, req.Path, &req.PlanValue)
+	req.ConfigValue = types.StringNull()
+
+	resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+	m.PlanModifyString(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	return resp
+}
+
+func ptr(value string) *string {
+	return &value
+}
+
+// stringIs checks a value is neither null nor unknown and equal to want.
+func stringIs(value types.String, want string) bool {
+	return !value.IsNull() && !value.IsUnknown() && value.ValueString() == want
+}
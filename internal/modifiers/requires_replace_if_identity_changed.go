@@ -0,0 +1,60 @@
+package modifiers
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// AttributeGetter reads attributes of a plan or a state.
+type AttributeGetter interface {
+	GetAttribute(ctx context.Context, path path.Path, target interface{}) diag.Diagnostics
+}
+
+// IdentityFunc returns the identity a resource is authenticated as, such as the user
+// owning a PAT, or "" when it can not be told, e.g. from unknown values.
+type IdentityFunc func(ctx context.Context, data AttributeGetter) (string, diag.Diagnostics)
+
+// RequiresReplaceIfIdentityChanged replaces the resource when the identity it is owned by
+// changes, as the new identity can neither read nor revoke what the previous one created.
+func RequiresReplaceIfIdentityChanged(identity IdentityFunc) planmodifier.String {
+	return requiresReplaceIfIdentityChangedModifier{identity: identity}
+}
+
+type requiresReplaceIfIdentityChangedModifier struct {
+	identity IdentityFunc
+}
+
+func (m requiresReplaceIfIdentityChangedModifier) Description(ctx context.Context) string {
+	return "If the identity authenticating the resource changes, Terraform will destroy and recreate the resource."
+}
+
+func (m requiresReplaceIfIdentityChangedModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m requiresReplaceIfIdentityChangedModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// Nothing owned yet on create, nor anymore on destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	stateIdentity, diags := m.identity(ctx, req.State)
+	resp.Diagnostics.Append(diags...)
+	planIdentity, diags := m.identity(ctx, req.Plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || stateIdentity == "" || planIdentity == "" {
+		return
+	}
+
+	if stateIdentity != planIdentity {
+		resp.RequiresReplace = true
+		// Terraform only replaces for attributes planned to change, computed ones are thus unknown
+		if req.ConfigValue.IsNull() {
+			resp.PlanValue = types.StringUnknown()
+		}
+	}
+}
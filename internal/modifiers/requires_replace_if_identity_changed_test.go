@@ -0,0 +1,45 @@
+package modifiers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestRequiresReplaceIfIdentityChanged(t *testing.T) {
+	// owners are case insensitive usernames
+	m := RequiresReplaceIfIdentityChanged(func(ctx context.Context, data AttributeGetter) (string, diag.Diagnostics) {
+		var owner types.String
+		diags := data.GetAttribute(ctx, path.Root("owner"), &owner)
+		return strings.ToLower(owner.ValueString()), diags
+	})
+
+	for name, tc := range map[string]struct {
+		state, plan     map[string]*string
+		requiresReplace bool
+	}{
+		"create":         {state: nil, plan: map[string]*string{"owner": ptr("user@myorganization.com")}},
+		"destroy":        {state: map[string]*string{"owner": ptr("user@myorganization.com")}, plan: nil},
+		"same owner":     {state: map[string]*string{"owner": ptr("user@myorganization.com")}, plan: map[string]*string{"owner": ptr("User@MyOrganization.com")}},
+		"imported":       {state: map[string]*string{"owner": nil}, plan: map[string]*string{"owner": ptr("user@myorganization.com")}},
+		"owner changed":  {state: map[string]*string{"owner": ptr("user@myorganization.com")}, plan: map[string]*string{"owner": ptr("admin@myorganization.com")}, requiresReplace: true},
+		"value kept too": {state: map[string]*string{"owner": ptr("user@myorganization.com"), "value": ptr("pat-1")}, plan: map[string]*string{"owner": ptr("user@myorganization.com"), "value": ptr("pat-1")}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := planModifyString(t, m, tc.state, tc.plan)
+			if resp.RequiresReplace != tc.requiresReplace {
+				t.Errorf("expected RequiresReplace %t, got %t", tc.requiresReplace, resp.RequiresReplace)
+			}
+			if tc.plan != nil && tc.plan["value"] != nil && !stringIs(resp.PlanValue, *tc.plan["value"]) {
+				t.Errorf("planned value must be kept, got %s", resp.PlanValue)
+			}
+			if tc.requiresReplace && !resp.PlanValue.IsUnknown() {
+				t.Errorf("computed value must be unknown once replaced, got %s", resp.PlanValue)
+			}
+		})
+	}
+}
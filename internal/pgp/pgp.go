@@ -0,0 +1,90 @@
+// Package pgp encrypts secrets for a PGP public key, so that they are only
+// stored in state as ciphertext.
+package pgp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+const KEYBASE_PREFIX string = "keybase:"
+
+// PublicKey is the key secrets are encrypted for.
+type PublicKey struct {
+	entity *openpgp.Entity
+}
+
+// ParsePublicKey reads a base64 encoded or armored public key, or a 'keybase:<username>'
+// reference resolved offline from '<keybaseDir>/<username>.asc' as exported by 'keybase pgp export'.
+func ParsePublicKey(pgpKey string, keybaseDir string) (*PublicKey, error) {
+	content := []byte(strings.TrimSpace(pgpKey))
+
+	if username, ok := strings.CutPrefix(pgpKey, KEYBASE_PREFIX); ok {
+		if username == "" || strings.ContainsAny(username, `/\`) {
+			return nil, fmt.Errorf("invalid keybase username %q", username)
+		}
+		if keybaseDir == "" {
+			return nil, fmt.Errorf("%s needs the provider pgp_keybase_dir holding %s.asc", pgpKey, username)
+		}
+		file, err := os.ReadFile(filepath.Join(keybaseDir, username+".asc"))
+		if err != nil {
+			return nil, fmt.Errorf("could not read the public key of %s: %w", pgpKey, err)
+		}
+		content = file
+	}
+
+	var entities openpgp.EntityList
+	var err error
+	if bytes.Contains(content, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----")) {
+		entities, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(content))
+	} else {
+		decoded, decodeErr := base64.StdEncoding.DecodeString(string(content))
+		if decodeErr != nil {
+			return nil, fmt.Errorf("public key is neither base64 encoded nor armored: %w", decodeErr)
+		}
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(decoded))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read public key: %w", err)
+	}
+	if len(entities) != 1 {
+		return nil, fmt.Errorf("expected a single public key, got %d", len(entities))
+	}
+	return &PublicKey{entity: entities[0]}, nil
+}
+
+// Fingerprint returns the hex encoded fingerprint of the primary key.
+func (k *PublicKey) Fingerprint() string {
+	return hex.EncodeToString(k.entity.PrimaryKey.Fingerprint)
+}
+
+// Encrypt returns the armored PGP message of plaintext, only readable with the private key.
+func (k *PublicKey) Encrypt(plaintext string) (string, error) {
+	buf := &bytes.Buffer{}
+	armorWriter, err := armor.Encode(buf, "PGP MESSAGE", nil)
+	if err != nil {
+		return "", err
+	}
+	writer, err := openpgp.Encrypt(armorWriter, []*openpgp.Entity{k.entity}, nil, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("could not encrypt for key %s: %w", k.Fingerprint(), err)
+	}
+	if _, err := writer.Write([]byte(plaintext)); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	if err := armorWriter.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
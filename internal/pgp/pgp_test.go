@@ -0,0 +1,117 @@
+package pgp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+func newTestEntity(t *testing.T) *openpgp.Entity {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("gitops", "", "gitops@myorganization.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+func decrypt(t *testing.T, entity *openpgp.Entity, message string) string {
+	t.Helper()
+
+	block, err := armor.Decode(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("message is not armored: %v", err)
+	}
+	md, err := openpgp.ReadMessage(block.Body, openpgp.EntityList{entity}, nil, nil)
+	if err != nil {
+		t.Fatalf("could not decrypt message: %v", err)
+	}
+	plaintext, err := io.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(plaintext)
+}
+
+func TestParsePublicKey_Base64(t *testing.T) {
+	entity := newTestEntity(t)
+	buf := &bytes.Buffer{}
+	if err := entity.Serialize(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ParsePublicKey(base64.StdEncoding.EncodeToString(buf.Bytes()), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key.Fingerprint() != strings.ToLower(entity.PrimaryKey.KeyIdString()) && !strings.HasSuffix(key.Fingerprint(), strings.ToLower(entity.PrimaryKey.KeyIdString())) {
+		t.Errorf("fingerprint %s does not match key ID %s", key.Fingerprint(), entity.PrimaryKey.KeyIdString())
+	}
+
+	message, err := key.Encrypt("secret-pat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(message, "-----BEGIN PGP MESSAGE-----") {
+		t.Errorf("expected an armored message, got %s", message)
+	}
+	if got := decrypt(t, entity, message); got != "secret-pat" {
+		t.Errorf("expected secret-pat, got %s", got)
+	}
+}
+
+func TestParsePublicKey_Keybase(t *testing.T) {
+	entity := newTestEntity(t)
+	dir := t.TempDir()
+	buf := &bytes.Buffer{}
+	armorWriter, err := armor.Encode(buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(armorWriter); err != nil {
+		t.Fatal(err)
+	}
+	armorWriter.Close()
+	if err := os.WriteFile(filepath.Join(dir, "gitops.asc"), buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ParsePublicKey("keybase:gitops", dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	message, err := key.Encrypt("secret-pat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decrypt(t, entity, message); got != "secret-pat" {
+		t.Errorf("expected secret-pat, got %s", got)
+	}
+
+	if _, err := ParsePublicKey("keybase:unknown", dir); err == nil {
+		t.Error("expected an error for a user without exported key")
+	}
+	if _, err := ParsePublicKey("keybase:gitops", ""); err == nil {
+		t.Error("expected an error without keybase directory")
+	}
+	if _, err := ParsePublicKey("keybase:../gitops", dir); err == nil {
+		t.Error("expected an error for a username escaping the keybase directory")
+	}
+}
+
+func TestParsePublicKey_Invalid(t *testing.T) {
+	if _, err := ParsePublicKey("not a key", ""); err == nil {
+		t.Error("expected an error for a value neither base64 nor armored")
+	}
+	if _, err := ParsePublicKey(base64.StdEncoding.EncodeToString([]byte("garbage")), ""); err == nil {
+		t.Error("expected an error for garbage key material")
+	}
+}
@@ -0,0 +1,267 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	ephemeralschema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/modifiers"
+)
+
+const (
+	AUTH_METHOD_PASSWORD           string = "password"
+	AUTH_METHOD_CLIENT_SECRET      string = "client_secret"
+	AUTH_METHOD_CLIENT_CERTIFICATE string = "client_certificate"
+	AUTH_METHOD_OIDC               string = "oidc"
+	AUTH_METHOD_MANAGED_IDENTITY   string = "managed_identity"
+)
+
+// AzureAuthModel describes the attributes locating the Azure Devops organization
+// and authenticating to it, shared by the PAT resources and ephemeral resource.
+type AzureAuthModel struct {
+	AppClientID             types.String `tfsdk:"app_client_id"`
+	AppClientSecret         types.String `tfsdk:"app_client_secret"`
+	AppClientCertificate    types.String `tfsdk:"app_client_certificate"`
+	AppClientCertificatePwd types.String `tfsdk:"app_client_certificate_password"`
+	Authority               types.String `tfsdk:"authority"`
+	AzureDevopsUser         types.String `tfsdk:"azure_devops_user"`
+	AzureDevopsPassword     types.String `tfsdk:"azure_devops_password"`
+	AzureDevopsPatEndpoint  types.String `tfsdk:"azure_devops_pat_endpoint"`
+	AzureDevopsOrganization types.String `tfsdk:"azure_devops_organization"`
+	IsAppRegistrationPublic types.Bool   `tfsdk:"is_app_registration_public"`
+	SwitchPrivatePublic     types.Bool   `tfsdk:"az_cli_switch_private_app_public"`
+	SwitchPrivatePublicWait types.Int64  `tfsdk:"az_cli_switch_private_app_public_wait_delay"`
+	AuthMethod              types.String `tfsdk:"auth_method"`
+	OidcTokenFilePath       types.String `tfsdk:"oidc_token_file_path"`
+	OidcRequestURL          types.String `tfsdk:"oidc_request_url"`
+	OidcRequestToken        types.String `tfsdk:"oidc_request_token"`
+	OidcServiceConnectionID types.String `tfsdk:"oidc_azure_service_connection_id"`
+	ManagedIdentityClientID types.String `tfsdk:"managed_identity_client_id"`
+	ManagedIdentityEndpoint types.String `tfsdk:"managed_identity_endpoint"`
+	// Secrets read at apply time from a file or a command
+	AzureDevopsPasswordFile        types.String `tfsdk:"azure_devops_password_file"`
+	AzureDevopsPasswordCommand     types.String `tfsdk:"azure_devops_password_command"`
+	AppClientSecretFile            types.String `tfsdk:"app_client_secret_file"`
+	AppClientSecretCommand         types.String `tfsdk:"app_client_secret_command"`
+	AppClientCertificateFile       types.String `tfsdk:"app_client_certificate_file"`
+	AppClientCertificateCommand    types.String `tfsdk:"app_client_certificate_command"`
+	AppClientCertificatePwdFile    types.String `tfsdk:"app_client_certificate_password_file"`
+	AppClientCertificatePwdCommand types.String `tfsdk:"app_client_certificate_password_command"`
+}
+
+// hasUnknown tells whether any attribute is unknown, e.g. set from another resource not created yet.
+func (data *AzureAuthModel) hasUnknown() bool {
+	value := reflect.ValueOf(*data)
+	for i := 0; i < value.NumField(); i++ {
+		if v, ok := value.Field(i).Interface().(attr.Value); ok && v.IsUnknown() {
+			return true
+		}
+	}
+	return false
+}
+
+// getAuthMethod returns the configured auth method, falling back on
+// 'is_app_registration_public' for configurations predating 'auth_method'.
+func (data *AzureAuthModel) getAuthMethod() string {
+	if data.AuthMethod.ValueString() != "" {
+		return data.AuthMethod.ValueString()
+	}
+	// 'is_app_registration_public' defaults to true
+	if data.IsAppRegistrationPublic.IsNull() || data.IsAppRegistrationPublic.ValueBool() {
+		return AUTH_METHOD_PASSWORD
+	}
+	if data.AppClientCertificate.ValueString() != "" || data.AppClientCertificateFile.ValueString() != "" || data.AppClientCertificateCommand.ValueString() != "" {
+		return AUTH_METHOD_CLIENT_CERTIFICATE
+	}
+	return AUTH_METHOD_CLIENT_SECRET
+}
+
+// authIdentity returns the identity PATs are created for: the user with the password flow, the
+// service principal of the app, or the managed identity. It is "" while attributes are unknown.
+func authIdentity(ctx context.Context, data modifiers.AttributeGetter) (string, diag.Diagnostics) {
+	var auth AzureAuthModel
+	var diags diag.Diagnostics
+	for name, target := range map[string]*types.String{
+		"auth_method":                    &auth.AuthMethod,
+		"app_client_id":                  &auth.AppClientID,
+		"app_client_certificate":         &auth.AppClientCertificate,
+		"app_client_certificate_file":    &auth.AppClientCertificateFile,
+		"app_client_certificate_command": &auth.AppClientCertificateCommand,
+		"azure_devops_user":              &auth.AzureDevopsUser,
+		"managed_identity_client_id":     &auth.ManagedIdentityClientID,
+	} {
+		diags.Append(data.GetAttribute(ctx, path.Root(name), target)...)
+	}
+	diags.Append(data.GetAttribute(ctx, path.Root("is_app_registration_public"), &auth.IsAppRegistrationPublic)...)
+	if diags.HasError() || auth.AuthMethod.IsUnknown() || auth.IsAppRegistrationPublic.IsUnknown() {
+		return "", diags
+	}
+
+	var subject types.String
+	authMethod := auth.getAuthMethod()
+	switch authMethod {
+	case AUTH_METHOD_PASSWORD:
+		subject = auth.AzureDevopsUser
+	case AUTH_METHOD_MANAGED_IDENTITY:
+		if auth.ManagedIdentityClientID.IsNull() {
+			return authMethod + ":system-assigned", diags
+		}
+		subject = auth.ManagedIdentityClientID
+	default:
+		subject = auth.AppClientID
+	}
+	if subject.ValueString() == "" {
+		return "", diags
+	}
+	return authMethod + ":" + strings.ToLower(subject.ValueString()), diags
+}
+
+// tokenProvider returns the provider of AzureAD tokens matching the configured auth method,
+// reading the secrets given as '_file' or '_command'. Tokens are shared through cache when set.
+func (data *AzureAuthModel) tokenProvider(ctx context.Context, client *http.Client, cloud azdo.Cloud, cache *azdo.TokenCache) (azdo.TokenProvider, error) {
+	data, err := data.withCredentialSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := data.newTokenProvider(client, cloud)
+	if err != nil || cache == nil {
+		return tokens, err
+	}
+	return &azdo.CachedTokenProvider{
+		Provider: tokens,
+		Cache:    cache,
+		Key: azdo.TokenCacheKey(
+			data.getAuthMethod(), cloud.AuthorityURL(data.Authority.ValueString()), data.AppClientID.ValueString(),
+			data.AzureDevopsUser.ValueString(), data.AzureDevopsPassword.ValueString(),
+			data.AppClientSecret.ValueString(), data.AppClientCertificate.ValueString(), data.AppClientCertificatePwd.ValueString(),
+			data.OidcTokenFilePath.ValueString(), data.OidcRequestURL.ValueString(), data.OidcServiceConnectionID.ValueString(),
+			data.ManagedIdentityClientID.ValueString(), data.ManagedIdentityEndpoint.ValueString(),
+		),
+	}, nil
+}
+
+// newTokenProvider returns the provider of AzureAD tokens matching the configured auth method.
+func (data *AzureAuthModel) newTokenProvider(client *http.Client, cloud azdo.Cloud) (azdo.TokenProvider, error) {
+	authMethod := data.getAuthMethod()
+	if authMethod == AUTH_METHOD_MANAGED_IDENTITY {
+		return &azdo.ManagedIdentityTokenProvider{
+			Config: azdo.ManagedIdentityConfig{
+				ClientID: data.ManagedIdentityClientID.ValueString(),
+				Endpoint: data.ManagedIdentityEndpoint.ValueString(),
+			},
+			HTTPClient: client,
+		}, nil
+	}
+
+	if data.AppClientID.ValueString() == "" || data.Authority.ValueString() == "" {
+		return nil, fmt.Errorf("you need to set app_client_id, and authority or azure_devops_organization, with auth_method=%s", authMethod)
+	}
+	app := azdo.AppConfig{
+		ClientID:   data.AppClientID.ValueString(),
+		Authority:  data.Authority.ValueString(),
+		Cloud:      cloud,
+		HTTPClient: client,
+	}
+	switch authMethod {
+	case AUTH_METHOD_PASSWORD:
+		if data.AzureDevopsUser.ValueString() == "" || data.AzureDevopsPassword.ValueString() == "" {
+			return nil, fmt.Errorf("you need to set azure_devops_user, and azure_devops_password or its _file or _command variant, with auth_method=%s", authMethod)
+		}
+		return &azdo.PasswordTokenProvider{
+			App:                     app,
+			Username:                data.AzureDevopsUser.ValueString(),
+			Password:                data.AzureDevopsPassword.ValueString(),
+			SwitchPrivatePublic:     data.SwitchPrivatePublic.ValueBool(),
+			SwitchPrivatePublicWait: time.Duration(data.SwitchPrivatePublicWait.ValueInt64()) * time.Second,
+		}, nil
+	case AUTH_METHOD_CLIENT_SECRET:
+		if data.AppClientSecret.ValueString() == "" {
+			return nil, fmt.Errorf("you need to set app_client_secret, or its _file or _command variant, if is_app_registration_public=false")
+		}
+		return azdo.NewClientSecretTokenProvider(app, data.AppClientSecret.ValueString())
+	case AUTH_METHOD_CLIENT_CERTIFICATE:
+		if data.AppClientCertificate.ValueString() == "" {
+			return nil, fmt.Errorf("you need to set app_client_certificate, or its _file or _command variant, with auth_method=%s", authMethod)
+		}
+		return azdo.NewClientCertificateTokenProvider(app, data.AppClientCertificate.ValueString(), data.AppClientCertificatePwd.ValueString())
+	case AUTH_METHOD_OIDC:
+		return azdo.NewOidcTokenProvider(app, azdo.OidcConfig{
+			TokenFilePath:       data.OidcTokenFilePath.ValueString(),
+			RequestURL:          data.OidcRequestURL.ValueString(),
+			RequestToken:        data.OidcRequestToken.ValueString(),
+			ServiceConnectionID: data.OidcServiceConnectionID.ValueString(),
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported auth_method %q", authMethod)
+	}
+}
+
+// resourceAuthAttributes returns the attributes of AzureAuthModel as defined by the PAT resource schema,
+// for the other resources authenticating the same way.
+func resourceAuthAttributes(ctx context.Context) map[string]resourceschema.Attribute {
+	schemaResp := resource.SchemaResponse{}
+	NewAzurePatResource().Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	attributes := map[string]resourceschema.Attribute{}
+	modelType := reflect.TypeOf(AzureAuthModel{})
+	for i := 0; i < modelType.NumField(); i++ {
+		name := modelType.Field(i).Tag.Get("tfsdk")
+		attributes[name] = schemaResp.Schema.Attributes[name]
+	}
+	return attributes
+}
+
+// ephemeralAuthAttributes returns the attributes of AzureAuthModel as defined by the
+// PAT resource schema, converted to optional ephemeral resource attributes.
+func ephemeralAuthAttributes(ctx context.Context) map[string]ephemeralschema.Attribute {
+	return convertAuthAttributes(ctx, map[reflect.Type]ephemeralschema.Attribute{
+		reflect.TypeOf(resourceschema.StringAttribute{}): ephemeralschema.StringAttribute{},
+		reflect.TypeOf(resourceschema.BoolAttribute{}):   ephemeralschema.BoolAttribute{},
+		reflect.TypeOf(resourceschema.Int64Attribute{}):  ephemeralschema.Int64Attribute{},
+	})
+}
+
+// datasourceAuthAttributes returns the attributes of AzureAuthModel as defined by the
+// PAT resource schema, converted to optional data source attributes.
+func datasourceAuthAttributes(ctx context.Context) map[string]datasourceschema.Attribute {
+	return convertAuthAttributes(ctx, map[reflect.Type]datasourceschema.Attribute{
+		reflect.TypeOf(resourceschema.StringAttribute{}): datasourceschema.StringAttribute{},
+		reflect.TypeOf(resourceschema.BoolAttribute{}):   datasourceschema.BoolAttribute{},
+		reflect.TypeOf(resourceschema.Int64Attribute{}):  datasourceschema.Int64Attribute{},
+	})
+}
+
+// convertAuthAttributes converts the attributes of resourceAuthAttributes into the optional attributes
+// of another schema, targets holding its empty attribute for each resource attribute type. The fields
+// both attributes have are copied, so that descriptions and validators are only defined once. Plan
+// modifiers and defaults only exist in resources: attributes computed for their default are not computed.
+func convertAuthAttributes[A any](ctx context.Context, targets map[reflect.Type]A) map[string]A {
+	attributes := map[string]A{}
+	for name, attribute := range resourceAuthAttributes(ctx) {
+		source := reflect.ValueOf(attribute)
+		target := reflect.New(reflect.TypeOf(targets[source.Type()])).Elem()
+		for i := 0; i < target.NumField(); i++ {
+			field := source.FieldByName(target.Type().Field(i).Name)
+			if field.IsValid() && field.Type() == target.Field(i).Type() {
+				target.Field(i).Set(field)
+			}
+		}
+		target.FieldByName("Optional").SetBool(true)
+		target.FieldByName("Computed").SetBool(source.FieldByName("Computed").Bool() && source.FieldByName("Default").IsNil())
+		attributes[name] = target.Interface().(A)
+	}
+	return attributes
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	ephemeralschema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+)
+
+func TestConvertAuthAttributes(t *testing.T) {
+	ctx := context.Background()
+	resourceAttributes := resourceAuthAttributes(ctx)
+	ephemeralAttributes := ephemeralAuthAttributes(ctx)
+	datasourceAttributes := datasourceAuthAttributes(ctx)
+
+	for name, attribute := range resourceAttributes {
+		ephemeralAttribute, ok := ephemeralAttributes[name]
+		if !ok || !ephemeralAttribute.IsOptional() || ephemeralAttribute.GetMarkdownDescription() != attribute.GetMarkdownDescription() || ephemeralAttribute.IsSensitive() != attribute.IsSensitive() {
+			t.Errorf("expected ephemeral attribute %s to be optional and described as in the resource, got %#v", name, ephemeralAttribute)
+		}
+		datasourceAttribute, ok := datasourceAttributes[name]
+		if !ok || !datasourceAttribute.IsOptional() || datasourceAttribute.GetMarkdownDescription() != attribute.GetMarkdownDescription() || datasourceAttribute.IsSensitive() != attribute.IsSensitive() {
+			t.Errorf("expected data source attribute %s to be optional and described as in the resource, got %#v", name, datasourceAttribute)
+		}
+	}
+
+	// Resolved from the organization
+	if authority := ephemeralAttributes["authority"].(ephemeralschema.StringAttribute); !authority.Computed || len(authority.Validators) != len(resourceAttributes["authority"].(resourceschema.StringAttribute).Validators) {
+		t.Errorf("expected authority to stay computed with its validators, got %#v", authority)
+	}
+	// Only computed in the resource for its default
+	if public := datasourceAttributes["is_app_registration_public"].(datasourceschema.BoolAttribute); public.Computed {
+		t.Errorf("expected is_app_registration_public not to be computed, got %#v", public)
+	}
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var httpsURLRegexp = regexp.MustCompile(`^https://[^\s/]+(/\S*)?$`)
+
+// credentialSourceAttributes lists the attributes a secret can be given by, at most one can be set.
+var credentialSourceAttributes = [][]string{
+	{"azure_devops_password", "azure_devops_password_file", "azure_devops_password_command", "azure_devops_password_wo"},
+	{"app_client_secret", "app_client_secret_file", "app_client_secret_command", "app_client_secret_wo"},
+	{"app_client_certificate", "app_client_certificate_file", "app_client_certificate_command"},
+	{"app_client_certificate_password", "app_client_certificate_password_file", "app_client_certificate_password_command"},
+}
+
+var _ resource.ConfigValidator = authConfigValidator{}
+var _ ephemeral.ConfigValidator = authConfigValidator{}
+var _ datasource.ConfigValidator = authConfigValidator{}
+
+// authConfigValidator checks the combinations of AzureAuthModel attributes, so that
+// mistakes fail in 'terraform validate' rather than halfway through an apply.
+type authConfigValidator struct {
+	// tokenOnly is for the resources only acquiring tokens, which need a tenant but no PAT endpoint
+	tokenOnly bool
+}
+
+func (v authConfigValidator) Description(ctx context.Context) string {
+	return "Checks the attributes required by the auth method are set, and conflicting ones are not"
+}
+
+func (v authConfigValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v authConfigValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	v.validate(ctx, req.Config, &resp.Diagnostics)
+}
+
+func (v authConfigValidator) ValidateEphemeralResource(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	v.validate(ctx, req.Config, &resp.Diagnostics)
+}
+
+func (v authConfigValidator) ValidateDataSource(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	v.validate(ctx, req.Config, &resp.Diagnostics)
+}
+
+func (v authConfigValidator) validate(ctx context.Context, config tfsdk.Config, diags *diag.Diagnostics) {
+	// value returns the configured value of name, nil when the schema has no such attribute
+	value := func(name string) *types.String {
+		if _, ok := config.Schema.GetAttributes()[name]; !ok {
+			return nil
+		}
+		var v types.String
+		diags.Append(config.GetAttribute(ctx, path.Root(name), &v)...)
+		return &v
+	}
+	// set returns which of names are configured, unknown values count as configured
+	set := func(names ...string) []string {
+		configured := []string{}
+		for _, name := range names {
+			if v := value(name); v != nil && !v.IsNull() {
+				configured = append(configured, name)
+			}
+		}
+		return configured
+	}
+
+	for _, names := range credentialSourceAttributes {
+		if configured := set(names...); len(configured) > 1 {
+			diags.AddAttributeError(path.Root(configured[1]), "Conflicting Attributes", fmt.Sprintf("%s can not be set together", strings.Join(configured, " and ")))
+		}
+	}
+
+	if !v.tokenOnly && len(set("azure_devops_organization", "azure_devops_pat_endpoint")) == 0 {
+		diags.AddError("Missing Attribute", "One of azure_devops_organization or azure_devops_pat_endpoint is required")
+	}
+
+	// The auth method can only be told once its attributes are known
+	var auth AzureAuthModel
+	diags.Append(config.GetAttribute(ctx, path.Root("auth_method"), &auth.AuthMethod)...)
+	diags.Append(config.GetAttribute(ctx, path.Root("is_app_registration_public"), &auth.IsAppRegistrationPublic)...)
+	certificates := set(credentialSourceAttributes[2]...)
+	if diags.HasError() || auth.AuthMethod.IsUnknown() || auth.IsAppRegistrationPublic.IsUnknown() {
+		return
+	}
+	if len(certificates) > 0 {
+		auth.AppClientCertificate = types.StringValue(certificates[0])
+	}
+	authMethod := auth.getAuthMethod()
+
+	required := func(names ...string) {
+		if len(set(names...)) > 0 {
+			return
+		}
+		if names = schemaAttributes(config, names); len(names) == 1 {
+			diags.AddAttributeError(path.Root(names[0]), "Missing Attribute", fmt.Sprintf("%s is required with auth_method = %q", names[0], authMethod))
+		} else {
+			diags.AddAttributeError(path.Root(names[0]), "Missing Attribute", fmt.Sprintf("One of %s is required with auth_method = %q", strings.Join(names, ", "), authMethod))
+		}
+	}
+	if authMethod != AUTH_METHOD_MANAGED_IDENTITY {
+		required("app_client_id")
+		if v.tokenOnly {
+			required("authority", "azure_devops_organization")
+		}
+	}
+	switch authMethod {
+	case AUTH_METHOD_PASSWORD:
+		required("azure_devops_user")
+		required(credentialSourceAttributes[0]...)
+	case AUTH_METHOD_CLIENT_SECRET:
+		required(credentialSourceAttributes[1]...)
+	case AUTH_METHOD_CLIENT_CERTIFICATE:
+		required(credentialSourceAttributes[2]...)
+	}
+
+	var switchPrivatePublic types.Bool
+	diags.Append(config.GetAttribute(ctx, path.Root("az_cli_switch_private_app_public"), &switchPrivatePublic)...)
+	if switchPrivatePublic.ValueBool() && authMethod != AUTH_METHOD_PASSWORD {
+		diags.AddAttributeError(
+			path.Root("az_cli_switch_private_app_public"),
+			"Invalid Attribute Combination",
+			fmt.Sprintf("az_cli_switch_private_app_public only works with the public client flow, 'is_app_registration_public = true' and 'auth_method = \"password\"', got auth_method = %q", authMethod),
+		)
+	}
+}
+
+// schemaAttributes keeps the names the schema of config has.
+func schemaAttributes(config tfsdk.Config, names []string) []string {
+	known := []string{}
+	for _, name := range names {
+		if _, ok := config.Schema.GetAttributes()[name]; ok {
+			known = append(known, name)
+		}
+	}
+	return known
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// credentialSource is a secret attribute of AzureAuthModel along its '_file' and '_command'
+// variants, read at apply time so that the secret itself never reaches state.
+type credentialSource struct {
+	attribute string
+	value     *types.String
+	file      types.String
+	command   types.String
+	// isPath keeps the file path as value instead of its content, for binary PFX certificates
+	isPath bool
+}
+
+// credentialSourceError is a credential source that could not be read, reported on its attribute.
+type credentialSourceError struct {
+	attribute string
+	err       error
+}
+
+func (e *credentialSourceError) Error() string {
+	return fmt.Sprintf("%s: %v", e.attribute, e.err)
+}
+
+func (e *credentialSourceError) Unwrap() error {
+	return e.err
+}
+
+func (data *AzureAuthModel) credentialSources() []credentialSource {
+	return []credentialSource{
+		{attribute: "azure_devops_password", value: &data.AzureDevopsPassword, file: data.AzureDevopsPasswordFile, command: data.AzureDevopsPasswordCommand},
+		{attribute: "app_client_secret", value: &data.AppClientSecret, file: data.AppClientSecretFile, command: data.AppClientSecretCommand},
+		{attribute: "app_client_certificate", value: &data.AppClientCertificate, file: data.AppClientCertificateFile, command: data.AppClientCertificateCommand, isPath: true},
+		{attribute: "app_client_certificate_password", value: &data.AppClientCertificatePwd, file: data.AppClientCertificatePwdFile, command: data.AppClientCertificatePwdCommand},
+	}
+}
+
+// withCredentialSources returns a copy of the auth attributes with the secrets read from their
+// '_file' or '_command' attributes, the copy must never be saved into state.
+func (data *AzureAuthModel) withCredentialSources(ctx context.Context) (*AzureAuthModel, error) {
+	auth := *data
+	for _, source := range auth.credentialSources() {
+		switch {
+		case source.file.ValueString() != "":
+			filePath := source.file.ValueString()
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return nil, &credentialSourceError{attribute: source.attribute + "_file", err: err}
+			}
+			if source.isPath {
+				*source.value = types.StringValue(filePath)
+			} else {
+				*source.value = types.StringValue(trimTrailingNewline(string(content)))
+			}
+		case source.command.ValueString() != "":
+			output, err := runCredentialCommand(ctx, source.command.ValueString())
+			if err != nil {
+				return nil, &credentialSourceError{attribute: source.attribute + "_command", err: err}
+			}
+			*source.value = types.StringValue(output)
+		}
+	}
+	return &auth, nil
+}
+
+// runCredentialCommand runs command through the shell and returns what it printed on stdout.
+func runCredentialCommand(ctx context.Context, command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("command failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	output := trimTrailingNewline(stdout.String())
+	if output == "" {
+		return "", fmt.Errorf("command printed nothing")
+	}
+	return output, nil
+}
+
+func trimTrailingNewline(value string) string {
+	value = strings.TrimSuffix(value, "\n")
+	return strings.TrimSuffix(value, "\r")
+}
+
+// addAuthError reports err on the credential source attribute it comes from, or as a client error.
+func addAuthError(diags *diag.Diagnostics, format string, err error) {
+	var sourceErr *credentialSourceError
+	if errors.As(err, &sourceErr) {
+		diags.AddAttributeError(path.Root(sourceErr.attribute), "Invalid Credential Source", fmt.Sprintf("Could not read %s, got error %v", sourceErr.attribute, sourceErr.err))
+		return
+	}
+	diags.AddError("Client Error", fmt.Sprintf(format, err))
+}
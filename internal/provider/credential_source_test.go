@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestWithCredentialSources(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("usersuperpassword\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	certificateFile := filepath.Join(dir, "cert.pfx")
+	if err := os.WriteFile(certificateFile, []byte{0x30, 0x82}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data := &AzureAuthModel{
+		AzureDevopsPasswordFile:  types.StringValue(passwordFile),
+		AppClientSecretCommand:   types.StringValue("printf 'appsecret\\r\\n'"),
+		AppClientCertificateFile: types.StringValue(certificateFile),
+	}
+	auth, err := data.withCredentialSources(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth.AzureDevopsPassword.ValueString() != "usersuperpassword" {
+		t.Errorf("expected the trailing newline of the file to be trimmed, got %q", auth.AzureDevopsPassword.ValueString())
+	}
+	if auth.AppClientSecret.ValueString() != "appsecret" {
+		t.Errorf("expected the trailing newline of the command to be trimmed, got %q", auth.AppClientSecret.ValueString())
+	}
+	if auth.AppClientCertificate.ValueString() != certificateFile {
+		t.Errorf("expected the certificate file path to be kept, got %q", auth.AppClientCertificate.ValueString())
+	}
+	if !data.AzureDevopsPassword.IsNull() || !data.AppClientSecret.IsNull() {
+		t.Error("secrets must not be written back into the model saved into state")
+	}
+}
+
+func TestWithCredentialSources_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	for name, tc := range map[string]struct {
+		data      AzureAuthModel
+		attribute string
+	}{
+		"missing file": {
+			data:      AzureAuthModel{AzureDevopsPasswordFile: types.StringValue(filepath.Join(t.TempDir(), "missing"))},
+			attribute: "azure_devops_password_file",
+		},
+		"failing command": {
+			data:      AzureAuthModel{AppClientSecretCommand: types.StringValue("echo locked >&2; exit 1")},
+			attribute: "app_client_secret_command",
+		},
+		"empty command": {
+			data:      AzureAuthModel{AppClientCertificatePwdCommand: types.StringValue("true")},
+			attribute: "app_client_certificate_password_command",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.data.withCredentialSources(ctx)
+			var sourceErr *credentialSourceError
+			if !errors.As(err, &sourceErr) {
+				t.Fatalf("expected a credential source error, got %v", err)
+			}
+			if sourceErr.attribute != tc.attribute {
+				t.Errorf("expected error on %s, got %s", tc.attribute, sourceErr.attribute)
+			}
+
+			diags := diag.Diagnostics{}
+			addAuthError(&diags, "Could not get token: %v", err)
+			if diags.ErrorsCount() != 1 || diags.Errors()[0].Summary() != "Invalid Credential Source" {
+				t.Errorf("expected an attribute error, got %v", diags)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSource = &AzureDevopsAuditEventsDataSource{}
+var _ datasource.DataSourceWithConfigure = &AzureDevopsAuditEventsDataSource{}
+var _ datasource.DataSourceWithConfigValidators = &AzureDevopsAuditEventsDataSource{}
+
+func NewAzureDevopsAuditEventsDataSource() datasource.DataSource {
+	return &AzureDevopsAuditEventsDataSource{
+		cloud: azdo.CLOUDS[azdo.CLOUD_PUBLIC],
+	}
+}
+
+// AzureDevopsAuditEventsDataSource queries the audit log of an organization.
+type AzureDevopsAuditEventsDataSource struct {
+	client *http.Client
+	cloud  azdo.Cloud
+	tokens *azdo.TokenCache
+}
+
+// AzureDevopsAuditEventsDataSourceModel describes the data source data model.
+type AzureDevopsAuditEventsDataSourceModel struct {
+	AzureAuthModel
+	StartTime types.String                 `tfsdk:"start_time"`
+	EndTime   types.String                 `tfsdk:"end_time"`
+	Actions   []types.String               `tfsdk:"actions"`
+	MaxEvents types.Int64                  `tfsdk:"max_events"`
+	Events    []AzureDevopsAuditEventModel `tfsdk:"events"`
+}
+
+// AzureDevopsAuditEventModel describes an event of the audit log.
+type AzureDevopsAuditEventModel struct {
+	ID                      types.String `tfsdk:"id"`
+	CorrelationID           types.String `tfsdk:"correlation_id"`
+	ActionID                types.String `tfsdk:"action_id"`
+	Area                    types.String `tfsdk:"area"`
+	Category                types.String `tfsdk:"category"`
+	Timestamp               types.String `tfsdk:"timestamp"`
+	ActorUserID             types.String `tfsdk:"actor_user_id"`
+	ActorUPN                types.String `tfsdk:"actor_upn"`
+	ActorDisplayName        types.String `tfsdk:"actor_display_name"`
+	AuthenticationMechanism types.String `tfsdk:"authentication_mechanism"`
+	IPAddress               types.String `tfsdk:"ip_address"`
+	ScopeDisplayName        types.String `tfsdk:"scope_display_name"`
+	ProjectName             types.String `tfsdk:"project_name"`
+	Details                 types.String `tfsdk:"details"`
+	Data                    types.String `tfsdk:"data"`
+}
+
+func (d *AzureDevopsAuditEventsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_devops_audit_events"
+}
+
+func (d *AzureDevopsAuditEventsDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		authConfigValidator{},
+	}
+}
+
+func (d *AzureDevopsAuditEventsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := datasourceAuthAttributes(ctx)
+	attributes["start_time"] = schema.StringAttribute{
+		MarkdownDescription: "Only return the events from this date (RFC3339), Azure Devops keeps them for 90 days",
+		Required:            true,
+		Validators: []validator.String{
+			validators.RFC3339(),
+		},
+	}
+	attributes["end_time"] = schema.StringAttribute{
+		MarkdownDescription: "Only return the events before this date (RFC3339) (default: now)",
+		Optional:            true,
+		Validators: []validator.String{
+			validators.RFC3339(),
+		},
+	}
+	attributes["actions"] = schema.ListAttribute{
+		MarkdownDescription: "Only return the events of these action IDs, a trailing '*' matching any suffix, e.g. '[\"Token.*\"]' for every PAT and SSH key event (default: every event)",
+		ElementType:         types.StringType,
+		Optional:            true,
+		Validators: []validator.List{
+			listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+		},
+	}
+	attributes["max_events"] = schema.Int64Attribute{
+		MarkdownDescription: "Stop once this number of events is returned, the latest ones (default: no limit)",
+		Optional:            true,
+		Validators: []validator.Int64{
+			int64validator.AtLeast(1),
+		},
+	}
+	attributes["events"] = schema.ListNestedAttribute{
+		MarkdownDescription: "Matching events, the latest first",
+		Computed:            true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"id": schema.StringAttribute{
+					MarkdownDescription: "ID of the event",
+					Computed:            true,
+				},
+				"correlation_id": schema.StringAttribute{
+					MarkdownDescription: "ID shared by the events of the same operation",
+					Computed:            true,
+				},
+				"action_id": schema.StringAttribute{
+					MarkdownDescription: "What happened, e.g. 'Token.PatCreateEvent'",
+					Computed:            true,
+				},
+				"area": schema.StringAttribute{
+					MarkdownDescription: "Area of the action, e.g. 'Token'",
+					Computed:            true,
+				},
+				"category": schema.StringAttribute{
+					MarkdownDescription: "Category of the action, e.g. 'create', 'modify', 'remove' or 'access'",
+					Computed:            true,
+				},
+				"timestamp": schema.StringAttribute{
+					MarkdownDescription: "Date of the event (RFC3339)",
+					Computed:            true,
+				},
+				"actor_user_id": schema.StringAttribute{
+					MarkdownDescription: "ID of the identity who did it",
+					Computed:            true,
+				},
+				"actor_upn": schema.StringAttribute{
+					MarkdownDescription: "User principal name of the identity who did it, empty for service principals",
+					Computed:            true,
+				},
+				"actor_display_name": schema.StringAttribute{
+					MarkdownDescription: "Display name of the identity who did it",
+					Computed:            true,
+				},
+				"authentication_mechanism": schema.StringAttribute{
+					MarkdownDescription: "How the identity authenticated, e.g. 'AAD_Cookie' or 'PAT'",
+					Computed:            true,
+				},
+				"ip_address": schema.StringAttribute{
+					MarkdownDescription: "IP address the action came from",
+					Computed:            true,
+				},
+				"scope_display_name": schema.StringAttribute{
+					MarkdownDescription: "Organization or project the action applies to",
+					Computed:            true,
+				},
+				"project_name": schema.StringAttribute{
+					MarkdownDescription: "Project the action applies to, empty for organization actions",
+					Computed:            true,
+				},
+				"details": schema.StringAttribute{
+					MarkdownDescription: "Human readable description of the event",
+					Computed:            true,
+				},
+				"data": schema.StringAttribute{
+					MarkdownDescription: "JSON encoded data of the event depending on its action, e.g. the 'DisplayName' and 'Scopes' of a PAT, read with 'jsondecode'",
+					Computed:            true,
+				},
+			},
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Events of the organization audit log read from the Azure Devops audit API, e.g. to prove who created and revoked PATs during compliance reviews. Auditing must be enabled in the organization, and the identity needs the 'View audit log' permission",
+		Attributes:          attributes,
+	}
+}
+
+func (d *AzureDevopsAuditEventsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*HelloassoProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *HelloassoProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = providerData.Client
+	d.cloud = providerData.Cloud
+	d.tokens = providerData.Tokens
+}
+
+func (d *AzureDevopsAuditEventsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AzureDevopsAuditEventsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts := azdo.AuditLogOptions{EndTime: time.Now(), MaxEntries: int(data.MaxEvents.ValueInt64())}
+	// Both were checked to be RFC3339 by their validators
+	opts.StartTime, _ = time.Parse(time.RFC3339, data.StartTime.ValueString())
+	if !data.EndTime.IsNull() {
+		opts.EndTime, _ = time.Parse(time.RFC3339, data.EndTime.ValueString())
+	}
+	if !opts.EndTime.After(opts.StartTime) {
+		resp.Diagnostics.AddAttributeError(path.Root("end_time"), "Invalid Time Range", fmt.Sprintf("end_time %s must be after start_time %s", opts.EndTime.Format(time.RFC3339), data.StartTime.ValueString()))
+		return
+	}
+	for _, action := range data.Actions {
+		opts.Actions = append(opts.Actions, action.ValueString())
+	}
+
+	if err := data.resolveOrganization(ctx, d.client, d.cloud); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+		return
+	}
+	tokens, err := data.tokenProvider(ctx, d.client, d.cloud, d.tokens)
+	if err != nil {
+		addAuthError(&resp.Diagnostics, "Could not get token for the audit API: %v", err)
+		return
+	}
+	endpoint := d.cloud.AuditLogEndpoint(data.organizationName())
+	entries, err := azdo.QueryAuditLog(ctx, d.client, endpoint, tokens, d.cloud.AzureDevopsScope(), opts)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not query audit log %s, got error %v", endpoint, err))
+		return
+	}
+	tflog.Info(ctx, fmt.Sprintf("Read: %d audit events from %s", len(entries), endpoint))
+
+	data.Events = []AzureDevopsAuditEventModel{}
+	for _, entry := range entries {
+		eventData := "{}"
+		if len(entry.Data) > 0 && string(entry.Data) != "null" {
+			eventData = string(entry.Data)
+		}
+		data.Events = append(data.Events, AzureDevopsAuditEventModel{
+			ID:                      types.StringValue(entry.ID),
+			CorrelationID:           types.StringValue(entry.CorrelationID),
+			ActionID:                types.StringValue(entry.ActionID),
+			Area:                    types.StringValue(entry.Area),
+			Category:                types.StringValue(entry.Category),
+			Timestamp:               types.StringValue(entry.Timestamp.UTC().Format(time.RFC3339)),
+			ActorUserID:             types.StringValue(entry.ActorUserID),
+			ActorUPN:                types.StringValue(entry.ActorUPN),
+			ActorDisplayName:        types.StringValue(entry.ActorDisplayName),
+			AuthenticationMechanism: types.StringValue(entry.AuthenticationMechanism),
+			IPAddress:               types.StringValue(entry.IPAddress),
+			ScopeDisplayName:        types.StringValue(entry.ScopeDisplayName),
+			ProjectName:             types.StringValue(entry.ProjectName),
+			Details:                 types.StringValue(entry.Details),
+			Data:                    types.StringValue(eventData),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
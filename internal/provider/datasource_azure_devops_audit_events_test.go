@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	resourcetest "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdotest"
+)
+
+func testAccAzureDevopsAuditEventsConfig(server *azdotest.Server, startTime string, extra string) string {
+	return testAccAzurePatPasswordConfig(server, "1") + fmt.Sprintf(`
+data "helloasso_azure_devops_audit_events" "test" {
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = "usersuperpassword"
+  start_time                = %[4]q
+  actions                   = ["Token.*"]
+  %[5]s
+
+  depends_on = [helloasso_azure_pat.test]
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser, startTime, extra)
+}
+
+func TestAccAzureDevopsAuditEventsDataSource(t *testing.T) {
+	server := newTestAccServer(t, true)
+	startTime := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	server.AddAuditEvent(azdo.AuditLogEntry{ActionID: "Token.PatRevokeEvent", Area: "Token", Timestamp: time.Now().Add(-2 * time.Hour)})
+	server.AddAuditEvent(azdo.AuditLogEntry{ActionID: "Token.SshCreateEvent", Area: "Token", Timestamp: time.Now().Add(-30 * time.Minute)})
+	server.AddAuditEvent(azdo.AuditLogEntry{ActionID: "Token.PatUpdateEvent", Area: "Token", Timestamp: time.Now().Add(-20 * time.Minute)})
+	server.AddAuditEvent(azdo.AuditLogEntry{ActionID: "Git.RepositoryCreated", Area: "Git", Timestamp: time.Now().Add(-10 * time.Minute)})
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config:      testAccAzureDevopsAuditEventsConfig(server, startTime, `end_time = "2020-01-01T00:00:00Z"`),
+				ExpectError: regexp.MustCompile(`must\s+be\s+after\s+start_time`),
+			},
+			{
+				Config: testAccAzureDevopsAuditEventsConfig(server, startTime, ""),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.#", "3"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.0.action_id", "Token.PatCreateEvent"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.0.area", "Token"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.0.category", "create"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.0.actor_user_id", testAccUserID),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.0.actor_display_name", "Azure Devops User"),
+					resourcetest.TestCheckResourceAttrPair("data.helloasso_azure_devops_audit_events.test", "events.0.correlation_id", "helloasso_azure_pat.test", "pat_id"),
+					resourcetest.TestMatchResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.0.data", regexp.MustCompile(`"Scopes":"vso.code vso.packaging"`)),
+					resourcetest.TestMatchResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.0.timestamp", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T`)),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.1.action_id", "Token.PatUpdateEvent"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.1.data", "{}"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.2.action_id", "Token.SshCreateEvent"),
+				),
+			},
+			{
+				Config: testAccAzureDevopsAuditEventsConfig(server, startTime, "max_events = 2"),
+				Check:  resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_audit_events.test", "events.#", "2"),
+			},
+		},
+	})
+}
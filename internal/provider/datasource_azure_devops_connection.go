@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSource = &AzureDevopsConnectionDataSource{}
+var _ datasource.DataSourceWithConfigure = &AzureDevopsConnectionDataSource{}
+var _ datasource.DataSourceWithConfigValidators = &AzureDevopsConnectionDataSource{}
+
+func NewAzureDevopsConnectionDataSource() datasource.DataSource {
+	return &AzureDevopsConnectionDataSource{
+		cloud: azdo.CLOUDS[azdo.CLOUD_PUBLIC],
+	}
+}
+
+// AzureDevopsConnectionDataSource tells who the credentials authenticate as in an organization.
+type AzureDevopsConnectionDataSource struct {
+	client *http.Client
+	cloud  azdo.Cloud
+	tokens *azdo.TokenCache
+}
+
+// AzureDevopsConnectionDataSourceModel describes the data source data model.
+type AzureDevopsConnectionDataSourceModel struct {
+	AzureAuthModel
+	OrganizationURL   types.String `tfsdk:"organization_url"`
+	OrganizationID    types.String `tfsdk:"organization_id"`
+	TenantID          types.String `tfsdk:"tenant_id"`
+	DeploymentType    types.String `tfsdk:"deployment_type"`
+	UserID            types.String `tfsdk:"user_id"`
+	UserDescriptor    types.String `tfsdk:"user_descriptor"`
+	SubjectDescriptor types.String `tfsdk:"subject_descriptor"`
+	DisplayName       types.String `tfsdk:"display_name"`
+}
+
+func (d *AzureDevopsConnectionDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_devops_connection"
+}
+
+func (d *AzureDevopsConnectionDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		authConfigValidator{},
+	}
+}
+
+func (d *AzureDevopsConnectionDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := datasourceAuthAttributes(ctx)
+	attributes["organization_url"] = schema.StringAttribute{
+		MarkdownDescription: "URL of the organization connected to",
+		Computed:            true,
+	}
+	attributes["organization_id"] = schema.StringAttribute{
+		MarkdownDescription: "Instance ID of the organization",
+		Computed:            true,
+	}
+	attributes["tenant_id"] = schema.StringAttribute{
+		MarkdownDescription: "ID of the AzureAD tenant backing the organization",
+		Computed:            true,
+	}
+	attributes["deployment_type"] = schema.StringAttribute{
+		MarkdownDescription: "Deployment type of the organization, 'hosted' for Azure Devops Services",
+		Computed:            true,
+	}
+	attributes["user_id"] = schema.StringAttribute{
+		MarkdownDescription: "ID of the authenticated identity",
+		Computed:            true,
+	}
+	attributes["user_descriptor"] = schema.StringAttribute{
+		MarkdownDescription: "Descriptor of the authenticated identity",
+		Computed:            true,
+	}
+	attributes["subject_descriptor"] = schema.StringAttribute{
+		MarkdownDescription: "Subject descriptor of the authenticated identity, as used by the Graph and token admin APIs",
+		Computed:            true,
+	}
+	attributes["display_name"] = schema.StringAttribute{
+		MarkdownDescription: "Display name of the authenticated identity",
+		Computed:            true,
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Identity and organization the credentials authenticate as, read from the Azure Devops connectionData API, e.g. to troubleshoot PAT errors or in 'check' blocks",
+		Attributes:          attributes,
+	}
+}
+
+func (d *AzureDevopsConnectionDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*HelloassoProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *HelloassoProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = providerData.Client
+	d.cloud = providerData.Cloud
+	d.tokens = providerData.Tokens
+}
+
+func (d *AzureDevopsConnectionDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AzureDevopsConnectionDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := data.resolveOrganization(ctx, d.client, d.cloud); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+		return
+	}
+	organizationURL := data.organizationURL(d.cloud)
+
+	tokens, err := data.tokenProvider(ctx, d.client, d.cloud, d.tokens)
+	if err != nil {
+		addAuthError(&resp.Diagnostics, "Could not get token for connection data: %v", err)
+		return
+	}
+	connectionData, err := azdo.GetConnectionData(ctx, d.client, organizationURL, tokens, d.cloud.AzureDevopsScope())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not read connection data of %s, got error %v", organizationURL, err))
+		return
+	}
+	tenant, err := azdo.DiscoverOrganizationTenant(ctx, d.client, organizationURL)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not discover the tenant of %s, got error %v", organizationURL, err))
+		return
+	}
+	tflog.Info(ctx, fmt.Sprintf("Read: authenticated as %s in %s", connectionData.AuthenticatedUser.ProviderDisplayName, organizationURL))
+
+	data.OrganizationURL = types.StringValue(organizationURL)
+	data.OrganizationID = types.StringValue(connectionData.InstanceID)
+	data.TenantID = types.StringValue(tenant)
+	data.DeploymentType = types.StringValue(connectionData.DeploymentType)
+	data.UserID = types.StringValue(connectionData.AuthenticatedUser.ID)
+	data.UserDescriptor = types.StringValue(connectionData.AuthenticatedUser.Descriptor)
+	data.SubjectDescriptor = types.StringValue(connectionData.AuthenticatedUser.SubjectDescriptor)
+	data.DisplayName = types.StringValue(connectionData.AuthenticatedUser.ProviderDisplayName)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
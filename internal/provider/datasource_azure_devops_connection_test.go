@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	resourcetest "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdotest"
+)
+
+func TestAccAzureDevopsConnectionDataSource(t *testing.T) {
+	server := newTestAccServer(t, true)
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config: testAccFakeProviderConfig(server) + fmt.Sprintf(`
+data "helloasso_azure_devops_connection" "test" {
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = "usersuperpassword"
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_connection.test", "organization_url", server.URL+"/"+azdotest.ORGANIZATION),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_connection.test", "organization_id", azdotest.ORGANIZATION_ID),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_connection.test", "tenant_id", azdotest.TENANT_ID),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_connection.test", "deployment_type", "hosted"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_connection.test", "user_id", testAccUserID),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_connection.test", "user_descriptor", "aad.MGQwYzNlN2EtMmE2MS00ZjliLWEzYTgtNGY2YjBlMWM5ZDEx"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_connection.test", "display_name", "Azure Devops User"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_connection.test", "authority", server.URL+"/"+azdotest.TENANT_ID),
+				),
+			},
+		},
+	})
+}
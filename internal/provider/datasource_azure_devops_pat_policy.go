@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSource = &AzureDevopsPatPolicyDataSource{}
+var _ datasource.DataSourceWithConfigure = &AzureDevopsPatPolicyDataSource{}
+var _ datasource.DataSourceWithConfigValidators = &AzureDevopsPatPolicyDataSource{}
+
+func NewAzureDevopsPatPolicyDataSource() datasource.DataSource {
+	return &AzureDevopsPatPolicyDataSource{
+		cloud: azdo.CLOUDS[azdo.CLOUD_PUBLIC],
+	}
+}
+
+// AzureDevopsPatPolicyDataSource reads the PAT policy an organization enforces.
+type AzureDevopsPatPolicyDataSource struct {
+	client *http.Client
+	cloud  azdo.Cloud
+	tokens *azdo.TokenCache
+}
+
+// AzureDevopsPatPolicyDataSourceModel describes the data source data model.
+type AzureDevopsPatPolicyDataSourceModel struct {
+	AzureAuthModel
+	MaxLifespanDays   types.Int64 `tfsdk:"max_lifespan_days"`
+	RestrictFullScope types.Bool  `tfsdk:"restrict_full_scope"`
+	RestrictGlobal    types.Bool  `tfsdk:"restrict_global"`
+	RestrictCreation  types.Bool  `tfsdk:"restrict_creation"`
+}
+
+func (d *AzureDevopsPatPolicyDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_devops_pat_policy"
+}
+
+func (d *AzureDevopsPatPolicyDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		authConfigValidator{},
+	}
+}
+
+func (d *AzureDevopsPatPolicyDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := datasourceAuthAttributes(ctx)
+	attributes["max_lifespan_days"] = schema.Int64Attribute{
+		MarkdownDescription: "Maximum number of days PATs may be valid for, 0 when the organization enforces no maximum lifespan",
+		Computed:            true,
+	}
+	attributes["restrict_full_scope"] = schema.BoolAttribute{
+		MarkdownDescription: "Whether full-scoped PATs ('app_token') can not be created",
+		Computed:            true,
+	}
+	attributes["restrict_global"] = schema.BoolAttribute{
+		MarkdownDescription: "Whether PATs valid for all the organizations of the user can not be created",
+		Computed:            true,
+	}
+	attributes["restrict_creation"] = schema.BoolAttribute{
+		MarkdownDescription: "Whether only the users allowed by the administrators can create PATs",
+		Computed:            true,
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Experimental: PAT policy of an organization, as set by the administrators of its tenant, that 'helloasso_azure_pat' checks during plan with 'on_policy_violation'. Azure Devops documents no API for it, the policy is read from the 'policies' endpoint of the token admin API, an undocumented preview whose answer may change without notice. When the organization does not serve it, the data source warns and leaves the policy attributes null, and it fails on answers missing a policy field rather than reading them as a policy enforcing nothing",
+		Attributes:          attributes,
+	}
+}
+
+func (d *AzureDevopsPatPolicyDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*HelloassoProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *HelloassoProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = providerData.Client
+	d.cloud = providerData.Cloud
+	d.tokens = providerData.Tokens
+}
+
+func (d *AzureDevopsPatPolicyDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AzureDevopsPatPolicyDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := data.resolveOrganization(ctx, d.client, d.cloud); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+		return
+	}
+	tokens, err := data.tokenProvider(ctx, d.client, d.cloud, d.tokens)
+	if err != nil {
+		addAuthError(&resp.Diagnostics, "Could not get token for the token admin API: %v", err)
+		return
+	}
+	endpoint := azdo.TokenAdminEndpoint(data.AzureDevopsPatEndpoint.ValueString())
+	policy, err := azdo.NewTokenAdminClient(d.client, endpoint, tokens, d.cloud.AzureDevopsScope()).GetPatPolicy(ctx)
+	if azdo.IsPatPolicyUnavailable(err) {
+		resp.Diagnostics.AddWarning("PAT Policy Unavailable", fmt.Sprintf("The organization does not serve the PAT policy API from %s, a preview, the policy attributes are left null, got error %v", endpoint, err))
+		data.MaxLifespanDays = types.Int64Null()
+		data.RestrictFullScope = types.BoolNull()
+		data.RestrictGlobal = types.BoolNull()
+		data.RestrictCreation = types.BoolNull()
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not read PAT policy from %s, got error %v", endpoint, err))
+		return
+	}
+	tflog.Info(ctx, fmt.Sprintf("Read: PAT policy %+v", *policy))
+
+	data.MaxLifespanDays = types.Int64Value(policy.MaxLifespanDays)
+	data.RestrictFullScope = types.BoolValue(policy.RestrictFullScope)
+	data.RestrictGlobal = types.BoolValue(policy.RestrictGlobal)
+	data.RestrictCreation = types.BoolValue(policy.RestrictCreation)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	resourcetest "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdotest"
+)
+
+func testAccAzureDevopsPatPolicyDataSourceConfig(server *azdotest.Server) string {
+	return testAccFakeProviderConfig(server) + fmt.Sprintf(`
+data "helloasso_azure_devops_pat_policy" "test" {
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = "usersuperpassword"
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser)
+}
+
+func TestAccAzureDevopsPatPolicyDataSource(t *testing.T) {
+	server := newTestAccServer(t, true)
+	server.SetPatPolicy(azdo.PatPolicy{MaxLifespanDays: 90, RestrictGlobal: true})
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config: testAccAzureDevopsPatPolicyDataSourceConfig(server),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_pat_policy.test", "max_lifespan_days", "90"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_pat_policy.test", "restrict_full_scope", "false"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_pat_policy.test", "restrict_global", "true"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_pat_policy.test", "restrict_creation", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAzureDevopsPatPolicyDataSource_Unavailable(t *testing.T) {
+	server := newTestAccServer(t, true)
+	server.SetPatPolicyUnavailable(true)
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config: testAccAzureDevopsPatPolicyDataSourceConfig(server),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckNoResourceAttr("data.helloasso_azure_devops_pat_policy.test", "max_lifespan_days"),
+					resourcetest.TestCheckNoResourceAttr("data.helloasso_azure_devops_pat_policy.test", "restrict_full_scope"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSource = &AzureDevopsScopesDataSource{}
+
+func NewAzureDevopsScopesDataSource() datasource.DataSource {
+	return &AzureDevopsScopesDataSource{}
+}
+
+// AzureDevopsScopesDataSource lists the PAT scopes of the catalog embedded in the provider.
+type AzureDevopsScopesDataSource struct{}
+
+// AzureDevopsScopesDataSourceModel describes the data source data model.
+type AzureDevopsScopesDataSourceModel struct {
+	Area   types.String            `tfsdk:"area"`
+	Level  types.String            `tfsdk:"level"`
+	Names  []types.String          `tfsdk:"names"`
+	Scopes []AzureDevopsScopeModel `tfsdk:"scopes"`
+}
+
+// AzureDevopsScopeModel describes a scope of the catalog.
+type AzureDevopsScopeModel struct {
+	Name        types.String   `tfsdk:"name"`
+	Area        types.String   `tfsdk:"area"`
+	Level       types.String   `tfsdk:"level"`
+	Title       types.String   `tfsdk:"title"`
+	Description types.String   `tfsdk:"description"`
+	Implies     []types.String `tfsdk:"implies"`
+}
+
+func (d *AzureDevopsScopesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_devops_scopes"
+}
+
+func (d *AzureDevopsScopesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Azure Devops PAT scopes known to the provider, e.g. to build 'azure_devops_pat_scopes' or check scopes given to a module. The catalog is embedded in the provider, no API is called",
+		Attributes: map[string]schema.Attribute{
+			"area": schema.StringAttribute{
+				MarkdownDescription: "Only list the scopes of this area, e.g. 'code' for 'vso.code', 'vso.code_write'...",
+				Optional:            true,
+			},
+			"level": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Only list the scopes of this access level, one of '%s'", strings.Join(azdo.SCOPE_LEVELS, "', '")),
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(azdo.SCOPE_LEVELS...),
+				},
+			},
+			"names": schema.ListAttribute{
+				MarkdownDescription: "Names of the listed scopes, sorted",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"scopes": schema.ListNestedAttribute{
+				MarkdownDescription: "Listed scopes, sorted by name",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Name of the scope, e.g. 'vso.code_write'",
+							Computed:            true,
+						},
+						"area": schema.StringAttribute{
+							MarkdownDescription: "Area of the scope, e.g. 'code'",
+							Computed:            true,
+						},
+						"level": schema.StringAttribute{
+							MarkdownDescription: fmt.Sprintf("Access level of the scope, one of '%s'", strings.Join(azdo.SCOPE_LEVELS, "', '")),
+							Computed:            true,
+						},
+						"title": schema.StringAttribute{
+							MarkdownDescription: "Title of the scope, as shown when creating a PAT in Azure Devops",
+							Computed:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "What the scope grants",
+							Computed:            true,
+						},
+						"implies": schema.ListAttribute{
+							MarkdownDescription: "Scopes granted along with this one, e.g. 'vso.code' for 'vso.code_write'",
+							ElementType:         types.StringType,
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *AzureDevopsScopesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AzureDevopsScopesDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Names = []types.String{}
+	data.Scopes = []AzureDevopsScopeModel{}
+	for _, scope := range azdo.ScopeCatalog() {
+		if (data.Area.ValueString() != "" && scope.Area != data.Area.ValueString()) || (data.Level.ValueString() != "" && scope.Level != data.Level.ValueString()) {
+			continue
+		}
+		scopeData := AzureDevopsScopeModel{
+			Name:        types.StringValue(scope.Name),
+			Area:        types.StringValue(scope.Area),
+			Level:       types.StringValue(scope.Level),
+			Title:       types.StringValue(scope.Title),
+			Description: types.StringValue(scope.Description),
+			Implies:     []types.String{},
+		}
+		for _, implied := range scope.Implies {
+			scopeData.Implies = append(scopeData.Implies, types.StringValue(implied))
+		}
+		data.Names = append(data.Names, scopeData.Name)
+		data.Scopes = append(data.Scopes, scopeData)
+	}
+	tflog.Info(ctx, fmt.Sprintf("Read: %d scopes listed", len(data.Scopes)))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	resourcetest "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccAzureDevopsScopesDataSource(t *testing.T) {
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resourcetest.TestStep{
+			{
+				Config: `
+data "helloasso_azure_devops_scopes" "test" {
+  level = "admin"
+}
+`,
+				ExpectError: regexp.MustCompile(`value\s+must\s+be\s+one\s+of`),
+			},
+			{
+				Config: `
+data "helloasso_azure_devops_scopes" "all" {}
+
+data "helloasso_azure_devops_scopes" "code" {
+  area = "code"
+}
+
+data "helloasso_azure_devops_scopes" "code_write" {
+  area  = "code"
+  level = "write"
+}
+
+data "helloasso_azure_devops_scopes" "none" {
+  area = "unknown"
+}
+`,
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckTypeSetElemAttr("data.helloasso_azure_devops_scopes.all", "names.*", "vso.packaging_write"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.code", "names.#", "5"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.code", "names.0", "vso.code"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.code", "scopes.1.name", "vso.code_full"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.code", "scopes.1.level", "manage"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.code", "scopes.1.implies.#", "3"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.code_write", "names.#", "2"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.code_write", "scopes.0.name", "vso.code_status"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.code_write", "scopes.1.name", "vso.code_write"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.code_write", "scopes.1.area", "code"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.code_write", "scopes.1.title", "Code (read and write)"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.code_write", "scopes.1.implies.0", "vso.code"),
+					resourcetest.TestCheckResourceAttrSet("data.helloasso_azure_devops_scopes.code_write", "scopes.1.description"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.none", "names.#", "0"),
+					resourcetest.TestCheckResourceAttr("data.helloasso_azure_devops_scopes.none", "scopes.#", "0"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ ephemeral.EphemeralResource = &AzureAccessTokenEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &AzureAccessTokenEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigValidators = &AzureAccessTokenEphemeralResource{}
+
+func NewAzureAccessTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &AzureAccessTokenEphemeralResource{
+		cloud: azdo.CLOUDS[azdo.CLOUD_PUBLIC],
+	}
+}
+
+// AzureAccessTokenEphemeralResource defines the ephemeral resource implementation.
+type AzureAccessTokenEphemeralResource struct {
+	client *http.Client
+	cloud  azdo.Cloud
+	tokens *azdo.TokenCache
+}
+
+// AzureAccessTokenEphemeralResourceModel describes the ephemeral resource data model.
+type AzureAccessTokenEphemeralResourceModel struct {
+	AzureAuthModel
+	Scopes      types.List   `tfsdk:"scopes"`
+	AccessToken types.String `tfsdk:"access_token"`
+	ExpiresOn   types.String `tfsdk:"expires_on"`
+}
+
+func (r *AzureAccessTokenEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_access_token"
+}
+
+func (r *AzureAccessTokenEphemeralResource) ConfigValidators(ctx context.Context) []ephemeral.ConfigValidator {
+	return []ephemeral.ConfigValidator{
+		authConfigValidator{tokenOnly: true},
+	}
+}
+
+func (r *AzureAccessTokenEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	attributes := ephemeralAuthAttributes(ctx)
+	attributes["scopes"] = schema.ListAttribute{
+		MarkdownDescription: "Scopes to request the token for, e.g. '[\"https://graph.microsoft.com/.default\"]' (default: the Azure Devops scope of the provider 'environment', '[\"499b84ac-1321-427f-aa17-267ca6975798/.default\"]' in the public cloud)",
+		ElementType:         types.StringType,
+		Optional:            true,
+		Validators: []validator.List{
+			listvalidator.SizeAtLeast(1),
+			listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+		},
+	}
+	attributes["access_token"] = schema.StringAttribute{
+		MarkdownDescription: "Bearer access token",
+		Computed:            true,
+		Sensitive:           true,
+	}
+	attributes["expires_on"] = schema.StringAttribute{
+		MarkdownDescription: "Expiration date of the access token (RFC3339)",
+		Computed:            true,
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "AzureAD access token acquired with the same auth methods as 'helloasso_azure_pat', e.g. to call the Azure Devops or Graph REST APIs from 'http' data sources, never stored in plan nor state. 'azure_devops_organization' is only used to discover the tenant when 'authority' is not set",
+		Attributes:          attributes,
+	}
+}
+
+func (r *AzureAccessTokenEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*HelloassoProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *HelloassoProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = providerData.Client
+	r.cloud = providerData.Cloud
+	r.tokens = providerData.Tokens
+}
+
+func (r *AzureAccessTokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data AzureAccessTokenEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scopes := []string{r.cloud.AzureDevopsScope()}
+	if !data.Scopes.IsNull() {
+		resp.Diagnostics.Append(data.Scopes.ElementsAs(ctx, &scopes, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// The organization only locates the tenant, no PAT endpoint is needed
+	if data.AzureDevopsOrganization.ValueString() != "" {
+		if err := data.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+			return
+		}
+	}
+
+	tokens, err := data.tokenProvider(ctx, r.client, r.cloud, r.tokens)
+	if err != nil {
+		addAuthError(&resp.Diagnostics, "Could not get access token: %v", err)
+		return
+	}
+	token, err := tokens.GetToken(ctx, scopes)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not get access token, got error %v", err))
+		return
+	}
+	tflog.Info(ctx, fmt.Sprintf("Open: got access token expiring on %s", token.ExpiresOn.Format(time.RFC3339)))
+
+	data.AccessToken = types.StringValue(token.Token)
+	data.ExpiresOn = types.StringValue(token.ExpiresOn.UTC().Format(time.RFC3339))
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
+	resourcetest "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdotest"
+)
+
+// testAccCheckEchoedTokenAudience checks the access token echoed by resource was issued by the fake for audience.
+func testAccCheckEchoedTokenAudience(server *azdotest.Server, resource string, audience string) resourcetest.TestCheckFunc {
+	return func(s *terraform.State) error {
+		token := s.RootModule().Resources[resource].Primary.Attributes["data.access_token"]
+		if got, ok := server.TokenAudience(token); !ok || got != audience {
+			return fmt.Errorf("expected a token for %s, got %q", audience, got)
+		}
+		return nil
+	}
+}
+
+func TestAccAzureAccessTokenEphemeralResource(t *testing.T) {
+	server := newTestAccServer(t, false)
+	factories := testAccFakeProviderFactories(server)
+	factories["echo"] = echoprovider.NewProviderServer()
+	// The echo resource keeps the data it was created with, each step echoes into its own
+	config := func(echo string, attributes string) string {
+		return testAccFakeProviderConfig(server) + fmt.Sprintf(`
+ephemeral "helloasso_azure_access_token" "test" {
+  app_client_id              = %[1]q
+  is_app_registration_public = false
+  app_client_secret          = "appsecret"
+  %[2]s
+}
+
+provider "echo" {
+  data = ephemeral.helloasso_azure_access_token.test
+}
+
+resource "echo" %[3]q {}
+`, testAccClientID, attributes, echo)
+	}
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: factories,
+		Steps: []resourcetest.TestStep{
+			{
+				Config:      config("invalid", ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`One\s+of\s+authority,\s+azure_devops_organization\s+is\s+required`),
+			},
+			// The tenant is discovered from the organization, the token is for Azure Devops by default
+			{
+				Config: config("devops", fmt.Sprintf(`azure_devops_organization = %q`, azdotest.ORGANIZATION)),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckResourceAttrSet("echo.devops", "data.expires_on"),
+					testAccCheckEchoedTokenAudience(server, "echo.devops", azdo.AZ_DEVOPS_RESOURCE_ID),
+				),
+			},
+			{
+				Config: config("graph", fmt.Sprintf(`authority = %q
+  scopes    = [%q]`, azdotest.TENANT_ID, server.URL+"/.default")),
+				Check: testAccCheckEchoedTokenAudience(server, "echo.graph", server.URL),
+			},
+		},
+	})
+}
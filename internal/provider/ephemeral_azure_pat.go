@@ -0,0 +1,320 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/validators"
+)
+
+const (
+	EPHEMERAL_PAT_DEFAULT_VALIDITY_MINUTES int64 = 60
+	// EPHEMERAL_PAT_PRIVATE_KEY is the private data key of the opened PAT
+	EPHEMERAL_PAT_PRIVATE_KEY string = "pat"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ ephemeral.EphemeralResource = &AzurePatEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &AzurePatEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithRenew = &AzurePatEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithClose = &AzurePatEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigValidators = &AzurePatEphemeralResource{}
+
+func NewAzurePatEphemeralResource() ephemeral.EphemeralResource {
+	r := &AzurePatEphemeralResource{
+		cloud:    azdo.CLOUDS[azdo.CLOUD_PUBLIC],
+		openPats: &OpenPats{},
+	}
+	r.newPatClient = func(endpoint string, tokens azdo.TokenProvider) azdo.PatClient {
+		return azdo.NewPatClient(r.client, endpoint, tokens, r.cloud.AzureDevopsScope())
+	}
+	return r
+}
+
+// AzurePatEphemeralResource defines the ephemeral resource implementation.
+type AzurePatEphemeralResource struct {
+	client   *http.Client
+	cloud    azdo.Cloud
+	openPats *OpenPats
+	tokens   *azdo.TokenCache
+	// newPatClient builds the client of the PAT API, tests swap it for a fake
+	newPatClient func(endpoint string, tokens azdo.TokenProvider) azdo.PatClient
+}
+
+// AzurePatEphemeralResourceModel describes the ephemeral resource data model.
+type AzurePatEphemeralResourceModel struct {
+	AzureAuthModel
+	PatName              types.String `tfsdk:"pat_name"`
+	AzureDevopsPatScopes types.String `tfsdk:"azure_devops_pat_scopes"`
+	ValidityMinutes      types.Int64  `tfsdk:"validity_minutes"`
+	Pat                  types.String `tfsdk:"pat"`
+	PatID                types.String `tfsdk:"pat_id"`
+	ValidTo              types.String `tfsdk:"valid_to"`
+}
+
+// OpenPats keeps the clients of the PATs opened by the ephemeral resource for the lifetime
+// of the provider process, so that credentials never reach private data.
+type OpenPats struct {
+	clients sync.Map
+}
+
+func (o *OpenPats) Store(authorizationID string, client azdo.PatClient) {
+	o.clients.Store(authorizationID, client)
+}
+
+func (o *OpenPats) Load(authorizationID string) azdo.PatClient {
+	client, ok := o.clients.Load(authorizationID)
+	if !ok {
+		return nil
+	}
+	return client.(azdo.PatClient)
+}
+
+func (o *OpenPats) Delete(authorizationID string) {
+	o.clients.Delete(authorizationID)
+}
+
+// ephemeralPatPrivate is what Renew and Close need to know of the opened PAT,
+// credentials stay in OpenPats and never reach private data.
+type ephemeralPatPrivate struct {
+	AuthorizationID string        `json:"authorization_id"`
+	DisplayName     string        `json:"display_name"`
+	Scope           string        `json:"scope"`
+	Validity        time.Duration `json:"validity"`
+}
+
+func (r *AzurePatEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_pat"
+}
+
+func (r *AzurePatEphemeralResource) ConfigValidators(ctx context.Context) []ephemeral.ConfigValidator {
+	return []ephemeral.ConfigValidator{
+		authConfigValidator{},
+	}
+}
+
+func (r *AzurePatEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	attributes := ephemeralAuthAttributes(ctx)
+	attributes["pat_name"] = schema.StringAttribute{
+		MarkdownDescription: "Name of PAT to create",
+		Required:            true,
+	}
+	attributes["azure_devops_pat_scopes"] = schema.StringAttribute{
+		MarkdownDescription: "Scopes of PAT token separated by a whitespace, see the 'helloasso_azure_devops_scopes' data source or https://github.com/MicrosoftDocs/azure-devops-docs/blob/main/docs/integrate/includes/scopes.md",
+		Required:            true,
+		Validators: []validator.String{
+			validators.DevopsScopes(),
+		},
+	}
+	attributes["validity_minutes"] = schema.Int64Attribute{
+		MarkdownDescription: fmt.Sprintf("Validity of the PAT, extended while terraform runs (default: %d)", EPHEMERAL_PAT_DEFAULT_VALIDITY_MINUTES),
+		Optional:            true,
+		Validators: []validator.Int64{
+			// Azure Devops PATs are valid a year at most, as the 'validity_days' of the resource
+			int64validator.Between(1, PAT_DEFAULT_VALIDITY_DAYS*24*60),
+		},
+	}
+	attributes["pat"] = schema.StringAttribute{
+		MarkdownDescription: "PAT token",
+		Computed:            true,
+		Sensitive:           true,
+	}
+	attributes["pat_id"] = schema.StringAttribute{
+		MarkdownDescription: "PAT ID",
+		Computed:            true,
+	}
+	attributes["valid_to"] = schema.StringAttribute{
+		MarkdownDescription: "Expiration date of the PAT when opened (RFC3339)",
+		Computed:            true,
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Short-lived PAT created when terraform needs it and revoked once the run is over, never stored in plan nor state",
+		Attributes:          attributes,
+	}
+}
+
+func (r *AzurePatEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*HelloassoProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *HelloassoProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = providerData.Client
+	r.cloud = providerData.Cloud
+	r.tokens = providerData.Tokens
+	r.openPats = providerData.OpenPats
+}
+
+func (r *AzurePatEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data AzurePatEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validityMinutes := EPHEMERAL_PAT_DEFAULT_VALIDITY_MINUTES
+	if !data.ValidityMinutes.IsNull() {
+		validityMinutes = data.ValidityMinutes.ValueInt64()
+	}
+	validity := time.Duration(validityMinutes) * time.Minute
+
+	if err := data.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+		return
+	}
+
+	tokens, err := data.tokenProvider(ctx, r.client, r.cloud, r.tokens)
+	if err != nil {
+		addAuthError(&resp.Diagnostics, "Could not get token for PAT creation: %v", err)
+		return
+	}
+	client := r.newPatClient(data.AzureDevopsPatEndpoint.ValueString(), tokens)
+
+	patToken, err := client.Create(ctx, azdo.CreatePatRequest{
+		DisplayName: data.PatName.ValueString(),
+		Scope:       data.AzureDevopsPatScopes.ValueString(),
+		ValidTo:     time.Now().Add(validity),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not get token, check app registration Public status, got error %v", err))
+		return
+	}
+	r.openPats.Store(patToken.AuthorizationID, client)
+
+	private, err := json.Marshal(ephemeralPatPrivate{
+		AuthorizationID: patToken.AuthorizationID,
+		DisplayName:     patToken.DisplayName,
+		Scope:           patToken.Scope,
+		Validity:        validity,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Internal Error", fmt.Sprintf("Could not save PAT %s private data: %v", patToken.AuthorizationID, err))
+	} else {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, EPHEMERAL_PAT_PRIVATE_KEY, private)...)
+	}
+
+	data.Pat = types.StringValue(patToken.Token)
+	data.PatID = types.StringValue(patToken.AuthorizationID)
+	data.ValidTo = types.StringValue(patToken.ValidTo.Format(time.RFC3339))
+	resp.RenewAt = ephemeralPatRenewAt(patToken.ValidTo, validity)
+
+	if !resp.Diagnostics.HasError() {
+		resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+	}
+	// Close is not called on a failed Open, nor without private data to find the PAT
+	if resp.Diagnostics.HasError() {
+		r.abandon(ctx, client, patToken.AuthorizationID, &resp.Diagnostics)
+	}
+}
+
+// abandon revokes the PAT of a failed Open and forgets its client.
+func (r *AzurePatEphemeralResource) abandon(ctx context.Context, client azdo.PatClient, authorizationID string, diags *diag.Diagnostics) {
+	r.openPats.Delete(authorizationID)
+	tflog.Info(ctx, fmt.Sprintf("Open: revoke PAT %s of the failed open", authorizationID))
+	if err := client.Revoke(ctx, authorizationID); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Could not revoke PAT %s of the failed open, it will only expire at the end of its validity, got error %v", authorizationID, err))
+	}
+}
+
+// ephemeralPatRenewAt asks terraform to extend the PAT once four fifths of its validity elapsed.
+func ephemeralPatRenewAt(validTo time.Time, validity time.Duration) time.Time {
+	return validTo.Add(-validity / 5)
+}
+
+// openedPat decodes the private data of the opened PAT and returns it along with the
+// client it was created with, nil when the PAT was opened by another provider process.
+func (r *AzurePatEphemeralResource) openedPat(raw []byte) (*ephemeralPatPrivate, azdo.PatClient, error) {
+	if raw == nil {
+		return nil, nil, fmt.Errorf("no PAT private data")
+	}
+	private := &ephemeralPatPrivate{}
+	if err := json.Unmarshal(raw, private); err != nil {
+		return nil, nil, err
+	}
+	return private, r.openPats.Load(private.AuthorizationID), nil
+}
+
+func (r *AzurePatEphemeralResource) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	raw, diags := req.Private.GetKey(ctx, EPHEMERAL_PAT_PRIVATE_KEY)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	private, client, err := r.openedPat(raw)
+	if err != nil {
+		resp.Diagnostics.AddError("Internal Error", fmt.Sprintf("Could not read PAT private data: %v", err))
+		return
+	}
+	if client == nil {
+		resp.Diagnostics.AddWarning("PAT Not Renewed", fmt.Sprintf("PAT %s was opened by another provider process, it can not be renewed", private.AuthorizationID))
+		return
+	}
+
+	patToken, err := client.Update(ctx, azdo.UpdatePatRequest{
+		AuthorizationID: private.AuthorizationID,
+		DisplayName:     private.DisplayName,
+		Scope:           private.Scope,
+		ValidTo:         time.Now().Add(private.Validity),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not renew PAT %s, got error %v", private.AuthorizationID, err))
+		return
+	}
+	tflog.Info(ctx, fmt.Sprintf("Renew: PAT %s extended to %s", private.AuthorizationID, patToken.ValidTo.Format(time.RFC3339)))
+	resp.RenewAt = ephemeralPatRenewAt(patToken.ValidTo, private.Validity)
+}
+
+func (r *AzurePatEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	raw, diags := req.Private.GetKey(ctx, EPHEMERAL_PAT_PRIVATE_KEY)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	private, client, err := r.openedPat(raw)
+	if err != nil {
+		resp.Diagnostics.AddError("Internal Error", fmt.Sprintf("Could not read PAT private data: %v", err))
+		return
+	}
+	if client == nil {
+		resp.Diagnostics.AddWarning("PAT Not Revoked", fmt.Sprintf("PAT %s was opened by another provider process, it will only expire at the end of its validity", private.AuthorizationID))
+		return
+	}
+	defer r.openPats.Delete(private.AuthorizationID)
+
+	err = client.Revoke(ctx, private.AuthorizationID)
+	if azdo.IsPatNotFound(err) {
+		tflog.Info(ctx, fmt.Sprintf("Close: PAT %s was already revoked", private.AuthorizationID))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not revoke PAT %s, got error %v", private.AuthorizationID, err))
+	}
+}
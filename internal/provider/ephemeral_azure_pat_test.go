@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	ephemeralschema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
+	resourcetest "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdotest"
+)
+
+func TestAccAzurePatEphemeralResource(t *testing.T) {
+	server := newTestAccServer(t, true)
+	factories := testAccFakeProviderFactories(server)
+	factories["echo"] = echoprovider.NewProviderServer()
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: factories,
+		Steps: []resourcetest.TestStep{
+			{
+				Config: testAccFakeProviderConfig(server) + fmt.Sprintf(`
+ephemeral "helloasso_azure_pat" "test" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = "vso.code"
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = "usersuperpassword"
+  validity_minutes          = 30
+}
+
+provider "echo" {
+  data = ephemeral.helloasso_azure_pat.test
+}
+
+resource "echo" "test" {}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckResourceAttrSet("echo.test", "data.pat"),
+					func(s *terraform.State) error {
+						pats := server.Pats()
+						if len(pats) == 0 || len(server.ActivePats()) != 0 {
+							return fmt.Errorf("expected every opened PAT to be revoked on close, got %+v", pats)
+						}
+						if validity := pats[0].ValidTo.Sub(pats[0].ValidFrom); validity < 29*time.Minute || validity > 31*time.Minute {
+							return fmt.Errorf("expected a 30 minutes PAT, got %s", validity)
+						}
+						echoed := s.RootModule().Resources["echo.test"].Primary.Attributes["data.pat_id"]
+						for _, pat := range pats {
+							if pat.AuthorizationID == echoed {
+								return nil
+							}
+						}
+						return fmt.Errorf("echoed PAT %s was not created on the server", echoed)
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAzurePatEphemeralResource_OpenRevokesOnPrivateError(t *testing.T) {
+	ctx := context.Background()
+	client := newFakePatClient()
+	r := NewAzurePatEphemeralResource().(*AzurePatEphemeralResource)
+	r.newPatClient = func(_ string, _ azdo.TokenProvider) azdo.PatClient {
+		return client
+	}
+	schemaResp := ephemeral.SchemaResponse{}
+	r.Schema(ctx, ephemeral.SchemaRequest{}, &schemaResp)
+	nullValue := tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)
+
+	model := AzurePatEphemeralResourceModel{
+		AzureAuthModel:       testAzurePatModel().AzureAuthModel,
+		PatName:              types.StringValue("gitops"),
+		AzureDevopsPatScopes: types.StringValue("vso.code"),
+		ValidityMinutes:      types.Int64Null(),
+		Pat:                  types.StringNull(),
+		PatID:                types.StringNull(),
+		ValidTo:              types.StringNull(),
+	}
+	model.AzureDevopsPatEndpoint = types.StringNull()
+	config := tfsdk.State{Schema: schemaResp.Schema, Raw: nullValue}
+	if diags := config.Set(ctx, &model); diags.HasError() {
+		t.Fatalf("config diagnostics: %v", diags)
+	}
+
+	// Without private data, Close could not find the PAT
+	resp := &ephemeral.OpenResponse{Result: tfsdk.EphemeralResultData{Schema: schemaResp.Schema, Raw: nullValue}}
+	r.Open(ctx, ephemeral.OpenRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: config.Raw}}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected the open to fail")
+	}
+	if len(client.revoked) != 1 || len(client.tokens) != 0 {
+		t.Errorf("expected the PAT to be revoked, got revoked %v and active %v", client.revoked, client.tokens)
+	}
+	if r.openPats.Load(client.revoked[0]) != nil {
+		t.Errorf("expected PAT %s to be forgotten", client.revoked[0])
+	}
+}
+
+func TestAzurePatEphemeralResource_ValidityMinutes(t *testing.T) {
+	ctx := context.Background()
+	schemaResp := ephemeral.SchemaResponse{}
+	NewAzurePatEphemeralResource().Schema(ctx, ephemeral.SchemaRequest{}, &schemaResp)
+	attribute := schemaResp.Schema.Attributes["validity_minutes"].(ephemeralschema.Int64Attribute)
+
+	for minutes, valid := range map[int64]bool{
+		-1:                                  false,
+		0:                                   false,
+		1:                                   true,
+		PAT_DEFAULT_VALIDITY_DAYS * 24 * 60: true,
+		PAT_DEFAULT_VALIDITY_DAYS*24*60 + 1: false,
+	} {
+		resp := &validator.Int64Response{}
+		for _, v := range attribute.Validators {
+			v.ValidateInt64(ctx, validator.Int64Request{Path: path.Root("validity_minutes"), ConfigValue: types.Int64Value(minutes)}, resp)
+		}
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("expected validity_minutes %d valid %t, got %v", minutes, valid, resp.Diagnostics)
+		}
+	}
+}
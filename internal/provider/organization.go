@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+// resolveOrganization fills the PAT endpoint and authority derived from
+// 'azure_devops_organization' when they are not set explicitly.
+func (data *AzureAuthModel) resolveOrganization(ctx context.Context, client *http.Client, cloud azdo.Cloud) error {
+	organization := data.AzureDevopsOrganization.ValueString()
+
+	if data.AzureDevopsPatEndpoint.ValueString() == "" {
+		if organization == "" {
+			return fmt.Errorf("you need to set azure_devops_organization or azure_devops_pat_endpoint")
+		}
+		data.AzureDevopsPatEndpoint = types.StringValue(cloud.PatEndpoint(organization))
+	}
+
+	if data.Authority.ValueString() == "" {
+		if organization == "" || data.getAuthMethod() == AUTH_METHOD_MANAGED_IDENTITY {
+			data.Authority = types.StringNull()
+			return nil
+		}
+		tflog.Info(ctx, fmt.Sprintf("Discover tenant of organization %s", organization))
+		tenant, err := azdo.DiscoverOrganizationTenant(ctx, client, cloud.OrganizationURL(organization))
+		if err != nil {
+			return err
+		}
+		data.Authority = types.StringValue(cloud.AuthorityURL(tenant))
+	}
+
+	return nil
+}
+
+// organizationURL returns the URL of the organization APIs (https://dev.azure.com/<organization>).
+func (data *AzureAuthModel) organizationURL(cloud azdo.Cloud) string {
+	return cloud.OrganizationURL(data.organizationName())
+}
+
+// organizationName returns 'azure_devops_organization', or the organization of the PAT endpoint
+// (https://vssps.dev.azure.com/<organization>/_apis/tokens/pats) when not set.
+func (data *AzureAuthModel) organizationName() string {
+	if organization := data.AzureDevopsOrganization.ValueString(); organization != "" {
+		return organization
+	}
+	organizationURL := strings.TrimSuffix(data.AzureDevopsPatEndpoint.ValueString(), "/tokens/pats")
+	organizationURL = strings.TrimSuffix(organizationURL, "/_apis")
+	return organizationURL[strings.LastIndex(organizationURL, "/")+1:]
+}
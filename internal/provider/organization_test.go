@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+func TestAzureAuthModel_OrganizationURL(t *testing.T) {
+	cloud := azdo.CLOUDS[azdo.CLOUD_PUBLIC]
+
+	for name, test := range map[string]struct {
+		auth         AzureAuthModel
+		organization string
+	}{
+		"organization": {
+			auth:         AzureAuthModel{AzureDevopsOrganization: types.StringValue("myorganization")},
+			organization: "myorganization",
+		},
+		// The PAT endpoint is on vssps, the organization APIs are not
+		"pat endpoint": {
+			auth:         AzureAuthModel{AzureDevopsPatEndpoint: types.StringValue("https://vssps.dev.azure.com/myorganization/_apis/tokens/pats")},
+			organization: "myorganization",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if organization := test.auth.organizationName(); organization != test.organization {
+				t.Errorf("expected organization %s, got %s", test.organization, organization)
+			}
+			if organizationURL := test.auth.organizationURL(cloud); organizationURL != "https://dev.azure.com/"+test.organization {
+				t.Errorf("unexpected organization URL %s", organizationURL)
+			}
+		})
+	}
+}
@@ -5,13 +5,18 @@ import (
 	"net/http"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
 )
 
 // Ensure HelloassoProvider satisfies various provider interfaces.
 var _ provider.Provider = &HelloassoProvider{}
+var _ provider.ProviderWithEphemeralResources = &HelloassoProvider{}
 
 // HelloassoProvider defines the provider implementation.
 type HelloassoProvider struct {
@@ -19,10 +24,32 @@ type HelloassoProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+	// client is the HTTP client of every API call, tests swap it for the one of a fake server
+	client *http.Client
 }
 
 // HelloassoProviderModel describes the provider data model.
 type HelloassoProviderModel struct {
+	Environment             types.String `tfsdk:"environment"`
+	AuthorityHost           types.String `tfsdk:"authority_host"`
+	GraphEndpoint           types.String `tfsdk:"graph_endpoint"`
+	AzureDevopsResourceID   types.String `tfsdk:"azure_devops_resource_id"`
+	AzureDevopsURL          types.String `tfsdk:"azure_devops_url"`
+	AzureDevopsVsspsURL     types.String `tfsdk:"azure_devops_vssps_url"`
+	AzureDevopsAuditURL     types.String `tfsdk:"azure_devops_audit_url"`
+	PgpKeybaseDir           types.String `tfsdk:"pgp_keybase_dir"`
+	VerifyCredentialsOnPlan types.Bool   `tfsdk:"verify_credentials_on_plan"`
+}
+
+// HelloassoProviderData is handed to resources and data sources once the provider is configured.
+type HelloassoProviderData struct {
+	Client        *http.Client
+	Cloud         azdo.Cloud
+	OpenPats      *OpenPats
+	PgpKeybaseDir string
+	// Tokens shares AzureAD tokens between the resources of the provider process
+	Tokens                  *azdo.TokenCache
+	VerifyCredentialsOnPlan bool
 }
 
 func (p *HelloassoProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -32,7 +59,44 @@ func (p *HelloassoProvider) Metadata(ctx context.Context, req provider.MetadataR
 
 func (p *HelloassoProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		Attributes: map[string]schema.Attribute{},
+		Attributes: map[string]schema.Attribute{
+			"environment": schema.StringAttribute{
+				MarkdownDescription: "Azure cloud the tenants live in, one of 'public', 'usgovernment', 'china' or 'custom' (default: public). Azure Devops Services has no sovereign cloud endpoints: 'usgovernment' and 'china' change the AzureAD authority host and Microsoft Graph endpoint but keep the public Azure Devops URLs and resource ID, set 'custom' and the 'azure_devops_*' overrides for other Azure Devops hosts",
+				Optional:            true,
+			},
+			"authority_host": schema.StringAttribute{
+				MarkdownDescription: "With 'environment = \"custom\"', AzureAD authority host (default: https://login.microsoftonline.com)",
+				Optional:            true,
+			},
+			"graph_endpoint": schema.StringAttribute{
+				MarkdownDescription: "With 'environment = \"custom\"', Microsoft Graph base URL (default: https://graph.microsoft.com)",
+				Optional:            true,
+			},
+			"azure_devops_resource_id": schema.StringAttribute{
+				MarkdownDescription: "With 'environment = \"custom\"', AzureAD resource ID of Azure Devops (default: " + azdo.AZ_DEVOPS_RESOURCE_ID + ")",
+				Optional:            true,
+			},
+			"azure_devops_url": schema.StringAttribute{
+				MarkdownDescription: "With 'environment = \"custom\"', Azure Devops base URL, organizations are resolved under it (default: https://dev.azure.com)",
+				Optional:            true,
+			},
+			"azure_devops_vssps_url": schema.StringAttribute{
+				MarkdownDescription: "With 'environment = \"custom\"', Azure Devops identity (VSSPS) base URL, PAT endpoints are built under it (default: https://vssps.dev.azure.com)",
+				Optional:            true,
+			},
+			"azure_devops_audit_url": schema.StringAttribute{
+				MarkdownDescription: "With 'environment = \"custom\"', Azure Devops audit service base URL, audit logs are read under it (default: https://auditservice.dev.azure.com)",
+				Optional:            true,
+			},
+			"pgp_keybase_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory of the public keys exported with 'keybase pgp export', a 'pgp_key = \"keybase:<username>\"' is read offline from '<username>.asc' in it",
+				Optional:            true,
+			},
+			"verify_credentials_on_plan": schema.BoolAttribute{
+				MarkdownDescription: "Acquire a token and call the Azure Devops connectionData API during plan, so that broken credentials fail the plan rather than the apply. Skipped for resources with 'az_cli_switch_private_app_public', which would switch the app registration public during plan. Tokens are only cached inside the provider process, plan and apply each acquire their own (default: false)",
+				Optional:            true,
+			},
+		},
 	}
 }
 
@@ -45,23 +109,58 @@ func (p *HelloassoProvider) Configure(ctx context.Context, req provider.Configur
 		return
 	}
 
-	// Configuration values are now available.
-	// if data.Endpoint.IsNull() { /* ... */ }
+	cloud, err := azdo.NewCloud(data.Environment.ValueString(), azdo.Cloud{
+		AuthorityHost:         data.AuthorityHost.ValueString(),
+		GraphEndpoint:         data.GraphEndpoint.ValueString(),
+		AzureDevopsResourceID: data.AzureDevopsResourceID.ValueString(),
+		AzureDevopsURL:        data.AzureDevopsURL.ValueString(),
+		AzureDevopsVsspsURL:   data.AzureDevopsVsspsURL.ValueString(),
+		AzureDevopsAuditURL:   data.AzureDevopsAuditURL.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("environment"), "Invalid Environment", err.Error())
+		return
+	}
 
-	// Example client configuration for data sources and resources
-	client := http.DefaultClient
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	client := p.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	providerData := &HelloassoProviderData{
+		Client:                  client,
+		Cloud:                   cloud,
+		OpenPats:                &OpenPats{},
+		PgpKeybaseDir:           data.PgpKeybaseDir.ValueString(),
+		Tokens:                  &azdo.TokenCache{},
+		VerifyCredentialsOnPlan: data.VerifyCredentialsOnPlan.ValueBool(),
+	}
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
+	resp.EphemeralResourceData = providerData
 }
 
 func (p *HelloassoProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewAzurePatResource,
+		NewAzurePatReaperResource,
+		NewAzureDevopsPatRevocationResource,
+	}
+}
+
+func (p *HelloassoProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewAzurePatEphemeralResource,
+		NewAzureAccessTokenEphemeralResource,
 	}
 }
 
 func (p *HelloassoProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewAzureDevopsConnectionDataSource,
+		NewAzureDevopsScopesDataSource,
+		NewAzureDevopsPatPolicyDataSource,
+		NewAzureDevopsAuditEventsDataSource,
+	}
 }
 
 func New(version string) func() provider.Provider {
@@ -1,10 +1,13 @@
 package provider
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdotest"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -20,3 +23,27 @@ func testAccPreCheck(t *testing.T) {
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+// testAccFakeProviderFactories instantiate a provider calling the fake server
+// through its TLS client, so acceptance tests run without network.
+func testAccFakeProviderFactories(server *azdotest.Server) map[string]func() (tfprotov6.ProviderServer, error) {
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"helloasso": providerserver.NewProtocol6WithError(&HelloassoProvider{version: "test", client: server.Client()}),
+	}
+}
+
+// testAccFakeProviderConfig points every endpoint of a custom environment at the fake server,
+// attributes are added to the provider block.
+func testAccFakeProviderConfig(server *azdotest.Server, attributes ...string) string {
+	return fmt.Sprintf(`
+provider "helloasso" {
+  environment            = "custom"
+  authority_host         = %[1]q
+  graph_endpoint         = %[1]q
+  azure_devops_url       = %[1]q
+  azure_devops_vssps_url = %[1]q
+  azure_devops_audit_url = %[1]q
+  %[2]s
+}
+`, server.URL, strings.Join(attributes, "\n  "))
+}
@@ -0,0 +1,253 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &AzureDevopsPatRevocationResource{}
+var _ resource.ResourceWithValidateConfig = &AzureDevopsPatRevocationResource{}
+var _ resource.ResourceWithConfigValidators = &AzureDevopsPatRevocationResource{}
+
+func NewAzureDevopsPatRevocationResource() resource.Resource {
+	r := &AzureDevopsPatRevocationResource{
+		cloud: azdo.CLOUDS[azdo.CLOUD_PUBLIC],
+	}
+	r.newTokenAdminClient = func(endpoint string, tokens azdo.TokenProvider) azdo.TokenAdminClient {
+		return azdo.NewTokenAdminClient(r.client, endpoint, tokens, r.cloud.AzureDevopsScope())
+	}
+	return r
+}
+
+// AzureDevopsPatRevocationResource revokes PATs of any user of an organization, e.g. leaked ones.
+type AzureDevopsPatRevocationResource struct {
+	client *http.Client
+	cloud  azdo.Cloud
+	tokens *azdo.TokenCache
+	// newTokenAdminClient builds the client of the token admin API, tests swap it for a fake
+	newTokenAdminClient func(endpoint string, tokens azdo.TokenProvider) azdo.TokenAdminClient
+}
+
+// AzureDevopsPatRevocationResourceModel describes the resource data model.
+type AzureDevopsPatRevocationResourceModel struct {
+	AzureAuthModel
+	UserDescriptors types.Set    `tfsdk:"user_descriptors"`
+	PatIDs          types.Set    `tfsdk:"pat_ids"`
+	Reason          types.String `tfsdk:"reason"`
+	RevokedPatIDs   types.Set    `tfsdk:"revoked_pat_ids"`
+	RevokedAt       types.String `tfsdk:"revoked_at"`
+}
+
+func (r *AzureDevopsPatRevocationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_devops_pat_revocation"
+}
+
+func (r *AzureDevopsPatRevocationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := resourceAuthAttributes(ctx)
+	attributes["user_descriptors"] = schema.SetAttribute{
+		MarkdownDescription: "Subject descriptors of the users or service principals whose every valid PAT is revoked, e.g. 'aad.MGQwYzNl...' as read from 'data.helloasso_azure_devops_connection.subject_descriptor'",
+		ElementType:         types.StringType,
+		Optional:            true,
+		Validators: []validator.Set{
+			setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+		},
+		PlanModifiers: []planmodifier.Set{
+			setplanmodifier.RequiresReplace(),
+		},
+	}
+	attributes["pat_ids"] = schema.SetAttribute{
+		MarkdownDescription: "Authorization IDs of the PATs to revoke, whoever owns them, e.g. as found in the 'AuthorizationId' of 'data.helloasso_azure_devops_audit_events'",
+		ElementType:         types.StringType,
+		Optional:            true,
+		Validators: []validator.Set{
+			setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+		},
+		PlanModifiers: []planmodifier.Set{
+			setplanmodifier.RequiresReplace(),
+		},
+	}
+	attributes["reason"] = schema.StringAttribute{
+		MarkdownDescription: "Why the PATs are revoked, e.g. an incident ticket, logged and kept in the state for the audit trail",
+		Optional:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+	attributes["revoked_pat_ids"] = schema.SetAttribute{
+		MarkdownDescription: "Authorization IDs of the PATs revoked on create, the valid PATs of 'user_descriptors' along with 'pat_ids'",
+		ElementType:         types.StringType,
+		Computed:            true,
+		PlanModifiers: []planmodifier.Set{
+			setplanmodifier.UseStateForUnknown(),
+		},
+	}
+	attributes["revoked_at"] = schema.StringAttribute{
+		MarkdownDescription: "Date of the revocation (RFC3339)",
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Revokes on create the PATs of 'user_descriptors' and 'pat_ids' through the token admin API, e.g. when they leaked, and records what was revoked. The identity must be an administrator of the organization, e.g. a confidential app or a managed identity added to the 'Project Collection Administrators' group. Nothing is revoked again until a replacement, nor restored on destroy",
+		Attributes:          attributes,
+	}
+}
+
+func (r *AzureDevopsPatRevocationResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		authConfigValidator{},
+	}
+}
+
+func (r *AzureDevopsPatRevocationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data AzureDevopsPatRevocationResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() || data.UserDescriptors.IsUnknown() || data.PatIDs.IsUnknown() {
+		return
+	}
+
+	if len(data.UserDescriptors.Elements()) == 0 && len(data.PatIDs.Elements()) == 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("pat_ids"), "Missing Attribute", "One of user_descriptors or pat_ids is required")
+	}
+}
+
+func (r *AzureDevopsPatRevocationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*HelloassoProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *HelloassoProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = providerData.Client
+	r.cloud = providerData.Cloud
+	r.tokens = providerData.Tokens
+}
+
+// revoke revokes pat_ids and the valid PATs of user_descriptors, and returns their IDs.
+func (r *AzureDevopsPatRevocationResource) revoke(ctx context.Context, data *AzureDevopsPatRevocationResourceModel) ([]string, error) {
+	if err := data.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+		return nil, fmt.Errorf("could not resolve Azure Devops organization: %w", err)
+	}
+	tokens, err := data.tokenProvider(ctx, r.client, r.cloud, r.tokens)
+	if err != nil {
+		return nil, err
+	}
+	client := r.newTokenAdminClient(azdo.TokenAdminEndpoint(data.AzureDevopsPatEndpoint.ValueString()), tokens)
+
+	revoked := map[string]bool{}
+	for _, patID := range data.PatIDs.Elements() {
+		revoked[patID.(types.String).ValueString()] = true
+	}
+	for _, descriptor := range data.UserDescriptors.Elements() {
+		sessionTokens, err := client.ListUserPats(ctx, descriptor.(types.String).ValueString())
+		if err != nil {
+			return nil, fmt.Errorf("could not list the PATs of %s: %w", descriptor.(types.String).ValueString(), err)
+		}
+		for _, sessionToken := range sessionTokens {
+			if sessionToken.IsValid {
+				revoked[sessionToken.AuthorizationID] = true
+			}
+		}
+	}
+
+	patIDs := make([]string, 0, len(revoked))
+	for patID := range revoked {
+		patIDs = append(patIDs, patID)
+	}
+	sort.Strings(patIDs)
+	if len(patIDs) == 0 {
+		return patIDs, nil
+	}
+	tflog.Info(ctx, fmt.Sprintf("Revoke: PATs %s, reason %q", strings.Join(patIDs, ", "), data.Reason.ValueString()))
+	if err := client.RevokeAuthorizations(ctx, patIDs); err != nil {
+		return nil, err
+	}
+	return patIDs, nil
+}
+
+func (r *AzureDevopsPatRevocationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *AzureDevopsPatRevocationResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	patIDs, err := r.revoke(ctx, data)
+	var apiErr *azdo.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden {
+		resp.Diagnostics.AddError("PAT Revocation Forbidden", fmt.Sprintf("Could not revoke PATs, the identity must be an administrator of the organization, got error %v", err))
+		return
+	}
+	if err != nil {
+		addAuthError(&resp.Diagnostics, "Could not revoke PATs, got error %v", err)
+		return
+	}
+	if len(patIDs) == 0 {
+		resp.Diagnostics.AddWarning("No PAT Revoked", "The users of user_descriptors have no valid PAT, and pat_ids is empty")
+	}
+
+	revokedPatIDs, diags := types.SetValueFrom(ctx, types.StringType, patIDs)
+	resp.Diagnostics.Append(diags...)
+	data.RevokedPatIDs = revokedPatIDs
+	data.RevokedAt = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AzureDevopsPatRevocationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Nothing to refresh, the state records the revocation done on create
+}
+
+func (r *AzureDevopsPatRevocationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data *AzureDevopsPatRevocationResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only the authentication may change in place, the PATs to revoke require a replacement
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AzureDevopsPatRevocationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	tflog.Info(ctx, "Delete state: revoked PATs stay revoked, nothing to delete")
+}
@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	resourcetest "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdotest"
+)
+
+const testAccAppObjectID = "8a5e3a53-4b7c-4b1c-9df0-3c0b7b7a3e21"
+
+func testAccAzureDevopsPatRevocationConfig(server *azdotest.Server, targets string) string {
+	return testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_devops_pat_revocation" "test" {
+  azure_devops_organization  = %[1]q
+  app_client_id              = %[2]q
+  is_app_registration_public = false
+  app_client_secret          = "appsecret"
+  %[3]s
+}
+`, azdotest.ORGANIZATION, testAccClientID, targets)
+}
+
+func TestAccAzureDevopsPatRevocationResource(t *testing.T) {
+	server := newTestAccServer(t, false)
+	server.AddUser(azdotest.User{
+		Username:    "leaker@myorganization.com",
+		Password:    "leakerpassword",
+		DisplayName: "Leaker",
+		ID:          "3b1f6c2e-2d4a-4e8b-9c7d-5a6b7c8d9e0f",
+		Descriptor:  "aad.M2IxZjZjMmUtMmQ0YS00ZThiLTljN2QtNWE2YjdjOGQ5ZTBm",
+	})
+	leakedCode := server.AddPat("3b1f6c2e-2d4a-4e8b-9c7d-5a6b7c8d9e0f", "laptop", "vso.code")
+	leakedBuild := server.AddPat("3b1f6c2e-2d4a-4e8b-9c7d-5a6b7c8d9e0f", "ci", "vso.build")
+	alreadyRevoked := server.AddPat("3b1f6c2e-2d4a-4e8b-9c7d-5a6b7c8d9e0f", "old", "vso.build")
+	server.RevokePat(alreadyRevoked.AuthorizationID)
+	leakedShared := server.AddPat(testAccUserID, "shared", "vso.packaging")
+	server.AddPat(testAccUserID, "gitops", "vso.code")
+
+	targets := fmt.Sprintf(`
+  user_descriptors = ["aad.M2IxZjZjMmUtMmQ0YS00ZThiLTljN2QtNWE2YjdjOGQ5ZTBm"]
+  pat_ids          = [%q]
+  reason           = "INC-1234 PATs pushed to a public repository"`, leakedShared.AuthorizationID)
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config:      testAccAzureDevopsPatRevocationConfig(server, `reason = "nothing"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`One\s+of\s+user_descriptors\s+or\s+pat_ids\s+is\s+required`),
+			},
+			// Only organization administrators revoke the PATs of others
+			{
+				Config:      testAccAzureDevopsPatRevocationConfig(server, targets),
+				ExpectError: regexp.MustCompile(`PAT\s+Revocation\s+Forbidden`),
+			},
+			{
+				PreConfig: func() {
+					server.SetTokenAdmins(testAccAppObjectID)
+				},
+				Config: testAccAzureDevopsPatRevocationConfig(server, targets),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckResourceAttr("helloasso_azure_devops_pat_revocation.test", "revoked_pat_ids.#", "3"),
+					resourcetest.TestCheckTypeSetElemAttr("helloasso_azure_devops_pat_revocation.test", "revoked_pat_ids.*", leakedCode.AuthorizationID),
+					resourcetest.TestCheckTypeSetElemAttr("helloasso_azure_devops_pat_revocation.test", "revoked_pat_ids.*", leakedBuild.AuthorizationID),
+					resourcetest.TestCheckTypeSetElemAttr("helloasso_azure_devops_pat_revocation.test", "revoked_pat_ids.*", leakedShared.AuthorizationID),
+					resourcetest.TestMatchResourceAttr("helloasso_azure_devops_pat_revocation.test", "revoked_at", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T`)),
+					testAccCheckPatsActive(server, map[string]bool{"laptop": false, "ci": false, "shared": false, "gitops": true}),
+				),
+			},
+			// PATs created since are left alone until a replacement
+			{
+				PreConfig: func() {
+					server.AddPat("3b1f6c2e-2d4a-4e8b-9c7d-5a6b7c8d9e0f", "new", "vso.code")
+				},
+				Config: testAccAzureDevopsPatRevocationConfig(server, targets),
+				ConfigPlanChecks: resourcetest.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: testAccCheckPatsActive(server, map[string]bool{"new": true}),
+			},
+		},
+	})
+}
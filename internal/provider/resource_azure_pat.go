@@ -1,81 +1,111 @@
 package provider
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
+	"math"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
 	"github.com/hashicorp/terraform-provider-helloasso/internal/modifiers"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/pgp"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/validators"
 
-	"os/exec"
-
-	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/confidential"
-	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/public"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 const (
-	AZ_SCOPE_DEVOPS                    string = "499b84ac-1321-427f-aa17-267ca6975798/.default"
-	PAT_API_VERSION                    string = "api-version=7.0-preview.1"
-	APP_API_ENDPOINT                   string = "https://graph.microsoft.com/v1.0/applications"
-	SWITCH_PRIVATE_PUBLIC_DEFAULT_WAIT int64  = 7
+	// PAT_DEFAULT_EXPIRY_WARNING_DAYS is how long before its expiry plans warn about a PAT
+	PAT_DEFAULT_EXPIRY_WARNING_DAYS int64 = 30
+	// PAT_DEFAULT_VALIDITY_DAYS is how long PATs are valid for, the longest Azure Devops allows
+	PAT_DEFAULT_VALIDITY_DAYS int64 = 365
+
+	ON_EXISTING_REVOKE string = "revoke"
+	ON_EXISTING_ADOPT  string = "adopt"
+	ON_EXISTING_ERROR  string = "error"
+
+	ON_POLICY_VIOLATION_IGNORE string = "ignore"
+	ON_POLICY_VIOLATION_CLAMP  string = "clamp"
+	ON_POLICY_VIOLATION_ERROR  string = "error"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &AzurePatResource{}
 var _ resource.ResourceWithImportState = &AzurePatResource{}
+var _ resource.ResourceWithValidateConfig = &AzurePatResource{}
+var _ resource.ResourceWithConfigValidators = &AzurePatResource{}
+var _ resource.ResourceWithModifyPlan = &AzurePatResource{}
 
 func NewAzurePatResource() resource.Resource {
-	return &AzurePatResource{}
+	r := &AzurePatResource{
+		cloud: azdo.CLOUDS[azdo.CLOUD_PUBLIC],
+	}
+	r.newPatClient = func(endpoint string, tokens azdo.TokenProvider) azdo.PatClient {
+		return azdo.NewPatClient(r.client, endpoint, tokens, r.cloud.AzureDevopsScope())
+	}
+	r.newTokenAdminClient = func(endpoint string, tokens azdo.TokenProvider) azdo.TokenAdminClient {
+		return azdo.NewTokenAdminClient(r.client, endpoint, tokens, r.cloud.AzureDevopsScope())
+	}
+	return r
 }
 
 // AzurePatResource defines the resource implementation.
 type AzurePatResource struct {
-	client *http.Client
+	client                  *http.Client
+	cloud                   azdo.Cloud
+	pgpKeybaseDir           string
+	tokens                  *azdo.TokenCache
+	verifyCredentialsOnPlan bool
+	// newPatClient builds the client of the PAT API, tests swap it for a fake
+	newPatClient func(endpoint string, tokens azdo.TokenProvider) azdo.PatClient
+	// newTokenAdminClient builds the client of the token admin API, tests swap it for a fake
+	newTokenAdminClient func(endpoint string, tokens azdo.TokenProvider) azdo.TokenAdminClient
 }
 
 // AzurePatResourceModel describes the resource data model.
 type AzurePatResourceModel struct {
-	PatName                 types.String `tfsdk:"pat_name"`
-	AppClientID             types.String `tfsdk:"app_client_id"`
-	AppClientSecret         types.String `tfsdk:"app_client_secret"`
-	Authority               types.String `tfsdk:"authority"`
-	AzureDevopsUser         types.String `tfsdk:"azure_devops_user"`
-	AzureDevopsPassword     types.String `tfsdk:"azure_devops_password"`
-	AzureDevopsPatEndpoint  types.String `tfsdk:"azure_devops_pat_endpoint"`
-	AzureDevopsPatScopes    types.String `tfsdk:"azure_devops_pat_scopes"`
-	IsAppRegistrationPublic types.Bool   `tfsdk:"is_app_registration_public"`
-	SwitchPrivatePublic     types.Bool   `tfsdk:"az_cli_switch_private_app_public"`
-	SwitchPrivatePublicWait types.Int64  `tfsdk:"az_cli_switch_private_app_public_wait_delay"`
-	RotateWhenChanged       types.String `tfsdk:"rotate_when_changed"`
-	Pat                     types.String `tfsdk:"pat"`
-	PatID                   types.String `tfsdk:"pat_id"`
-}
-
-type PatToken struct {
-	DisplayName     string   `json:"displayName"`
-	ValidTo         string   `json:"validTo"`
-	Scope           string   `json:"scope"`
-	TargetAccounts  []string `json:"targetAccounts"`
-	ValidFrom       string   `json:"validFrom"`
-	AuthorizationId string   `json:"authorizationId"`
-	Token           string   `json:"token"`
-}
-type PatCreationResponse struct {
-	PatToken      PatToken `json:"patToken"`
-	PatTokenError string   `json:"patTokenError"`
+	AzureAuthModel
+	PatName              types.String `tfsdk:"pat_name"`
+	AzureDevopsPatScopes types.String `tfsdk:"azure_devops_pat_scopes"`
+	RotateWhenChanged    types.String `tfsdk:"rotate_when_changed"`
+	ValidityDays         types.Int64  `tfsdk:"validity_days"`
+	OnPolicyViolation    types.String `tfsdk:"on_policy_violation"`
+	Pat                  types.String `tfsdk:"pat"`
+	PatID                types.String `tfsdk:"pat_id"`
+	PgpKey               types.String `tfsdk:"pgp_key"`
+	EncryptedPat         types.String `tfsdk:"encrypted_pat"`
+	KeyFingerprint       types.String `tfsdk:"key_fingerprint"`
+	WaitUntilUsable      types.Int64  `tfsdk:"wait_until_usable_timeout"`
+	OnExisting           types.String `tfsdk:"on_existing"`
+	RevokeOnDestroy      types.Bool   `tfsdk:"revoke_on_destroy"`
+	ExpiryWarningDays    types.Int64  `tfsdk:"expiry_warning_days"`
+	ValidTo              types.String `tfsdk:"valid_to"`
+	DaysUntilExpiry      types.Int64  `tfsdk:"days_until_expiry"`
+	// Write-only credentials, only set in the configuration during apply
+	AzureDevopsPasswordWO        types.String `tfsdk:"azure_devops_password_wo"`
+	AzureDevopsPasswordWOVersion types.Int64  `tfsdk:"azure_devops_password_wo_version"`
+	AppClientSecretWO            types.String `tfsdk:"app_client_secret_wo"`
+	AppClientSecretWOVersion     types.Int64  `tfsdk:"app_client_secret_wo_version"`
 }
 
 func (r *AzurePatResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -85,52 +115,99 @@ func (r *AzurePatResource) Metadata(ctx context.Context, req resource.MetadataRe
 func (r *AzurePatResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		// This description is used by the documentation generator and the language server.
-		MarkdownDescription: "Example resource",
+		MarkdownDescription: "Manages a PAT of an Azure Devops user, revoked on destroy, replacement and rotation unless 'revoke_on_destroy = false'. Prefer the '_file' and '_command' variants of 'azure_devops_password' and 'app_client_secret', read at apply time and never stored in state, over the plain attributes stored in plan and state. The write-only 'azure_devops_password_wo' and 'app_client_secret_wo' are not kept after apply either, so terraform can never revoke the PATs created with them: each destroy, replacement or rotation leaves the previous PAT valid until it expires. Revoke these orphans with 'helloasso_azure_pat_reaper' and 'managed_only', keeping the current 'pat_id' in 'keep_pat_ids'",
 
 		Attributes: map[string]schema.Attribute{
 			"pat_name": schema.StringAttribute{
-				MarkdownDescription: "Name of PAT to create",
+				MarkdownDescription: "Name of PAT to create, a '[tf-helloasso:<key>]' marker is appended to it while it is created to find the PATs whose creation never reached the state, see 'on_existing'. Once saved into state the marker becomes '[tf-helloasso]', and is removed on destroy with 'revoke_on_destroy = false'",
 				Required:            true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					stringplanmodifier.RequiresReplaceIf(
+						replaceUnlessImported,
+						"Changing it creates a new PAT, except on the first apply after an import",
+						"Changing it creates a new PAT, except on the first apply after an import",
+					),
 				},
 			},
 			"azure_devops_pat_scopes": schema.StringAttribute{
-				MarkdownDescription: "Scopes of PAT token separated by a whitespace, see https://github.com/MicrosoftDocs/azure-devops-docs/blob/main/docs/integrate/includes/scopes.md",
+				MarkdownDescription: "Scopes of PAT token separated by a whitespace, see the 'helloasso_azure_devops_scopes' data source or https://github.com/MicrosoftDocs/azure-devops-docs/blob/main/docs/integrate/includes/scopes.md",
 				Required:            true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					stringplanmodifier.RequiresReplaceIf(
+						replaceUnlessImported,
+						"Changing it creates a new PAT, except on the first apply after an import",
+						"Changing it creates a new PAT, except on the first apply after an import",
+					),
+				},
+				Validators: []validator.String{
+					validators.DevopsScopes(),
 				},
 			},
 			"app_client_id": schema.StringAttribute{
-				MarkdownDescription: "Client ID of registered app",
-				Required:            true,
+				MarkdownDescription: "Client ID of registered app, required unless 'auth_method = \"managed_identity\"'",
+				Optional:            true,
+				Validators: []validator.String{
+					validators.AzureGUID(),
+				},
 			},
 			"authority": schema.StringAttribute{
-				MarkdownDescription: "AzureAD authority URL",
-				Required:            true,
+				MarkdownDescription: "AzureAD authority URL, or tenant ID completed with the provider 'environment' authority host (default: tenant discovered from 'azure_devops_organization', not used with 'auth_method = \"managed_identity\"')",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				Validators: []validator.String{
+					validators.EntraAuthority(),
+				},
 			},
 			"azure_devops_user": schema.StringAttribute{
-				MarkdownDescription: "Username of Azure Devops user",
-				Required:            true,
+				MarkdownDescription: "Username of Azure Devops user, required with 'auth_method = \"password\"'",
+				Optional:            true,
 			},
 			"azure_devops_password": schema.StringAttribute{
-				MarkdownDescription: "Password of Azure Devops user",
-				Required:            true,
+				MarkdownDescription: "Password of Azure Devops user, required with 'auth_method = \"password\"'",
+				Optional:            true,
 				Sensitive:           true,
 			},
+			"azure_devops_password_file": schema.StringAttribute{
+				MarkdownDescription: "File containing the password of Azure Devops user, e.g. a Kubernetes or Docker secret, read at apply time and never stored in state",
+				Optional:            true,
+			},
+			"azure_devops_password_command": schema.StringAttribute{
+				MarkdownDescription: "Command printing the password of Azure Devops user, e.g. 'pass show azure/devops' or 'op read op://vault/devops/password', run at apply time and never stored in state",
+				Optional:            true,
+			},
+			"azure_devops_password_wo": schema.StringAttribute{
+				MarkdownDescription: "Write-only password of Azure Devops user, never stored in plan nor state (Terraform >= 1.11). As it is not kept after apply, the PAT is never revoked by terraform, neither on destroy nor on replacement or rotation: each of them leaves an orphan PAT valid until it expires, to revoke with 'helloasso_azure_pat_reaper'. Use 'azure_devops_password_file' or 'azure_devops_password_command' when the PAT must be revoked",
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+			},
+			"azure_devops_password_wo_version": schema.Int64Attribute{
+				MarkdownDescription: "Version of 'azure_devops_password_wo', change it when the password changes to check the new one during apply",
+				Optional:            true,
+			},
 			"azure_devops_pat_endpoint": schema.StringAttribute{
-				MarkdownDescription: "API endpoint to manage PATs",
-				Required:            true,
+				MarkdownDescription: "API endpoint to manage PATs (default: https://vssps.dev.azure.com/<azure_devops_organization>/_apis/tokens/pats)",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(httpsURLRegexp, "must be an https URL"),
+				},
+			},
+			"azure_devops_organization": schema.StringAttribute{
+				MarkdownDescription: "Name of the Azure Devops organization, used to derive 'azure_devops_pat_endpoint' and 'authority' when they are not set",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"is_app_registration_public": schema.BoolAttribute{
 				MarkdownDescription: `Is app registration managing authent public or confidential, if false need to set 'app_client_secret'
 															Warning WIP: must be 'true' as confidential app is not supported for now`,
 				Optional: true,
-
-				PlanModifiers: []planmodifier.Bool{
-					modifiers.DefaultBool(true),
-				},
+				Computed: true,
+				Default:  booldefault.StaticBool(true),
 			},
 			"az_cli_switch_private_app_public": schema.BoolAttribute{
 				MarkdownDescription: `This is a dirty workaround to be able to use confidential app with public flow
@@ -144,27 +221,213 @@ func (r *AzurePatResource) Schema(ctx context.Context, req resource.SchemaReques
 				MarkdownDescription: "When 'az_cli_switch_private_app_public = true' delay to wait for change propagation before acquiring token, (default: 7)",
 				Optional:            true,
 				PlanModifiers:       []planmodifier.Int64{int64planmodifier.UseStateForUnknown()},
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
 			},
 			"app_client_secret": schema.StringAttribute{
 				MarkdownDescription: "WIP (not supported): Client secret of registered app (to be set if is_app_registration_public=false)",
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"app_client_secret_file": schema.StringAttribute{
+				MarkdownDescription: "File containing the client secret of registered app, read at apply time and never stored in state",
+				Optional:            true,
+			},
+			"app_client_secret_command": schema.StringAttribute{
+				MarkdownDescription: "Command printing the client secret of registered app, run at apply time and never stored in state",
+				Optional:            true,
+			},
+			"app_client_secret_wo": schema.StringAttribute{
+				MarkdownDescription: "Write-only client secret of registered app, never stored in plan nor state (Terraform >= 1.11). As it is not kept after apply, the PAT is never revoked by terraform, neither on destroy nor on replacement or rotation: each of them leaves an orphan PAT valid until it expires, to revoke with 'helloasso_azure_pat_reaper'. Use 'app_client_secret_file' or 'app_client_secret_command' when the PAT must be revoked",
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+			},
+			"app_client_secret_wo_version": schema.Int64Attribute{
+				MarkdownDescription: "Version of 'app_client_secret_wo', change it when the secret changes to check the new one during apply",
+				Optional:            true,
+			},
+			"app_client_certificate": schema.StringAttribute{
+				MarkdownDescription: `Client certificate of registered app, used instead of 'app_client_secret' if is_app_registration_public=false
+										PEM (certificate and private key) or PFX, either inline (PFX base64 encoded) or as a file path`,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"app_client_certificate_file": schema.StringAttribute{
+				MarkdownDescription: "PEM or PFX file of the client certificate of registered app, read at apply time",
+				Optional:            true,
+			},
+			"app_client_certificate_command": schema.StringAttribute{
+				MarkdownDescription: "Command printing the client certificate of registered app, PEM or base64 encoded PFX, run at apply time and never stored in state",
+				Optional:            true,
+			},
+			"app_client_certificate_password": schema.StringAttribute{
+				MarkdownDescription: "Password of the 'app_client_certificate' private key or PFX",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"app_client_certificate_password_file": schema.StringAttribute{
+				MarkdownDescription: "File containing the password of the client certificate private key or PFX, read at apply time and never stored in state",
+				Optional:            true,
+			},
+			"app_client_certificate_password_command": schema.StringAttribute{
+				MarkdownDescription: "Command printing the password of the client certificate private key or PFX, run at apply time and never stored in state",
+				Optional:            true,
+			},
+			"auth_method": schema.StringAttribute{
+				MarkdownDescription: `How to get the AzureAD token used to manage PATs, one of 'password', 'client_secret', 'client_certificate', 'oidc' or 'managed_identity'
+										'oidc' exchanges a federated token from the CI (GitHub Actions, Azure Pipelines, workload identity) against an AD token
+										'managed_identity' gets the token of the Azure VM or App Service managed identity running terraform
+										default: 'password' if 'is_app_registration_public = true', else 'client_certificate' if 'app_client_certificate' is set, 'client_secret' otherwise`,
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(AUTH_METHOD_PASSWORD, AUTH_METHOD_CLIENT_SECRET, AUTH_METHOD_CLIENT_CERTIFICATE, AUTH_METHOD_OIDC, AUTH_METHOD_MANAGED_IDENTITY),
+				},
+			},
+			"oidc_token_file_path": schema.StringAttribute{
+				MarkdownDescription: "With 'auth_method = \"oidc\"', file containing the federated token (default: env AZURE_FEDERATED_TOKEN_FILE)",
+				Optional:            true,
+			},
+			"oidc_request_url": schema.StringAttribute{
+				MarkdownDescription: "With 'auth_method = \"oidc\"', URL to request the federated token from (default: env ACTIONS_ID_TOKEN_REQUEST_URL or SYSTEM_OIDCREQUESTURI)",
+				Optional:            true,
+			},
+			"oidc_request_token": schema.StringAttribute{
+				MarkdownDescription: "With 'auth_method = \"oidc\"', bearer token to call 'oidc_request_url' (default: env ACTIONS_ID_TOKEN_REQUEST_TOKEN or SYSTEM_ACCESSTOKEN)",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"oidc_azure_service_connection_id": schema.StringAttribute{
+				MarkdownDescription: "With 'auth_method = \"oidc\"' in Azure Pipelines, ID of the service connection to request the federated token for (default: env AZURESUBSCRIPTION_SERVICE_CONNECTION_ID or ARM_OIDC_AZURE_SERVICE_CONNECTION_ID)",
+				Optional:            true,
+			},
+			"managed_identity_client_id": schema.StringAttribute{
+				MarkdownDescription: "With 'auth_method = \"managed_identity\"', client ID of the user-assigned identity to use (default: system-assigned identity)",
+				Optional:            true,
+				Validators: []validator.String{
+					validators.AzureGUID(),
+				},
+			},
+			"managed_identity_endpoint": schema.StringAttribute{
+				MarkdownDescription: "With 'auth_method = \"managed_identity\"', token endpoint of the managed identity (default: env IDENTITY_ENDPOINT, or IMDS http://169.254.169.254/metadata/identity/oauth2/token)",
+				Optional:            true,
+			},
 			"rotate_when_changed": schema.StringAttribute{
 				MarkdownDescription: "Arbitrary map of values that, when changed, will trigger rotation of the PAT",
 				Optional:            true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					stringplanmodifier.RequiresReplaceIf(
+						replaceUnlessImported,
+						"Changing it creates a new PAT, except on the first apply after an import",
+						"Changing it creates a new PAT, except on the first apply after an import",
+					),
+				},
+			},
+			"validity_days": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Number of days the PAT is valid for, changing it creates a new PAT (default: %d)", PAT_DEFAULT_VALIDITY_DAYS),
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(PAT_DEFAULT_VALIDITY_DAYS),
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplaceIf(
+						validityDaysChanged,
+						"PATs created before validity_days existed are kept, others are created again",
+						"PATs created before 'validity_days' existed are kept, others are created again",
+					),
+				},
+				Validators: []validator.Int64{
+					int64validator.Between(1, PAT_DEFAULT_VALIDITY_DAYS),
+				},
+			},
+			"on_policy_violation": schema.StringAttribute{
+				MarkdownDescription: `Experimental: what to do during plan when a new PAT would break the organization PAT policy, see the 'helloasso_azure_devops_pat_policy' data source
+										'clamp' 'validity_days' to the maximum lifespan of the policy with a warning, or fail the plan with 'error'. Both fail the plan on full-scoped PATs ('app_token') the policy forbids
+										The policy is read from an undocumented preview endpoint of the token admin API: the checks are skipped with a warning when the organization does not serve it, and fail the plan when it answers an unexpected policy. They are also skipped during plan with 'az_cli_switch_private_app_public', which would switch the app public on every plan, 'clamp' still applies on creation
+										default: 'ignore', the policy is not read and Azure Devops rejects the PATs breaking it during apply`,
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(ON_POLICY_VIOLATION_IGNORE),
+				Validators: []validator.String{
+					stringvalidator.OneOf(ON_POLICY_VIOLATION_IGNORE, ON_POLICY_VIOLATION_CLAMP, ON_POLICY_VIOLATION_ERROR),
+				},
+			},
+			"pgp_key": schema.StringAttribute{
+				MarkdownDescription: "Base64 encoded PGP public key, or 'keybase:<username>' read from the provider 'pgp_keybase_dir', to encrypt the PAT for. When set, 'pat' is left empty and the PAT is only stored encrypted in 'encrypted_pat'",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(
+						replaceUnlessImported,
+						"Changing it creates a new PAT, except on the first apply after an import",
+						"Changing it creates a new PAT, except on the first apply after an import",
+					),
+				},
+			},
+			"wait_until_usable_timeout": schema.Int64Attribute{
+				MarkdownDescription: "Seconds to wait after creation for the PAT to authenticate against an Azure Devops API its scopes give access to, as new PATs take a while to propagate. The PAT is revoked if it is still not usable after that (default: 0, do not wait)",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"on_existing": schema.StringAttribute{
+				MarkdownDescription: `What to do on creation with the active PATs of this identity still marked as being created with the same name, scopes, 'rotate_when_changed', 'pgp_key' and 'validity_days', e.g. after a crash or a timeout during apply before the state was saved
+										'revoke' them before creating a new PAT, 'adopt' the latest one (its secret can not be read back, 'pat' is then empty) or fail with 'error'
+										PATs saved into state are renamed and never considered, including the ones kept by 'revoke_on_destroy = false'. Resources of the same identity creating PATs with the same attributes at the same time, e.g. in two workspaces, see each other's PAT being created: only set 'revoke' or 'adopt' with distinct 'pat_name'
+										default: 'error'`,
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(ON_EXISTING_ERROR),
+				Validators: []validator.String{
+					stringvalidator.OneOf(ON_EXISTING_REVOKE, ON_EXISTING_ADOPT, ON_EXISTING_ERROR),
 				},
 			},
+			"revoke_on_destroy": schema.BoolAttribute{
+				MarkdownDescription: "Revoke the PAT on destroy, replacement and rotation, set to false for the PAT to outlive the resource, e.g. when handed over to another team. Ignored with the write-only credentials, which can not revoke the PAT (default: true)",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"expiry_warning_days": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Plans warn about the PAT once it expires within this number of days, 0 to never warn (default: %d)", PAT_DEFAULT_EXPIRY_WARNING_DAYS),
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(PAT_DEFAULT_EXPIRY_WARNING_DAYS),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"valid_to": schema.StringAttribute{
+				MarkdownDescription: "Expiration date of the PAT (RFC3339)",
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"days_until_expiry": schema.Int64Attribute{
+				MarkdownDescription: "Number of whole days before the PAT expires as of the last refresh, negative once expired, e.g. for 'check' blocks",
+				Computed:            true,
+				PlanModifiers:       []planmodifier.Int64{int64planmodifier.UseStateForUnknown()},
+			},
 			"pat": schema.StringAttribute{
-				MarkdownDescription: "PAT token",
+				MarkdownDescription: "PAT token, empty when 'pgp_key' is set",
 				Computed:            true,
 				Sensitive:           true,
 				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
 			"pat_id": schema.StringAttribute{
-				MarkdownDescription: "PAT ID",
+				MarkdownDescription: "PAT ID, the PAT is created again when the identity owning it changes, e.g. 'azure_devops_user'",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					modifiers.RequiresReplaceIfIdentityChanged(authIdentity),
+				},
+			},
+			"encrypted_pat": schema.StringAttribute{
+				MarkdownDescription: "With 'pgp_key', PAT token encrypted as an armored PGP message, e.g. decrypted with 'terraform output -raw encrypted_pat | gpg --decrypt'",
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"key_fingerprint": schema.StringAttribute{
+				MarkdownDescription: "With 'pgp_key', fingerprint of the PGP key the PAT is encrypted for",
 				Computed:            true,
 				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
@@ -172,79 +435,432 @@ func (r *AzurePatResource) Schema(ctx context.Context, req resource.SchemaReques
 	}
 }
 
-func (r *AzurePatResource) getPublicAdToken(ctx context.Context, appID string, azureUser string, azurePassword string, authority string, apiScope string, switchPrivatePublic bool, switchPrivatePublicWait int64) (string, error) {
+// withWriteOnly returns the auth attributes completed with the write-only credentials
+// of config, so that they authenticate without ever being saved into state.
+func (data *AzurePatResourceModel) withWriteOnly(config *AzurePatResourceModel) *AzureAuthModel {
+	auth := data.AzureAuthModel
+	if config.AzureDevopsPasswordWO.ValueString() != "" {
+		auth.AzureDevopsPassword = config.AzureDevopsPasswordWO
+	}
+	if config.AppClientSecretWO.ValueString() != "" {
+		auth.AppClientSecret = config.AppClientSecretWO
+	}
+	return &auth
+}
 
-	tflog.Info(ctx, "getPublicAdToken")
-	// We add a workaround here for more security: make app public only while we get the token
-	// Since there is no AcquireTokenByUsernamePassword for Confidential App yet
-	azCmd := []string{"az", "ad", "app", "update", "--id", appID, "--is-fallback-public-client"}
-	if switchPrivatePublic {
-		appPublic := append(azCmd, "true")
+// usesWriteOnlyCredentials tells whether the credentials of the auth method were given
+// through write-only attributes, the PAT could not have been created otherwise.
+func (data *AzurePatResourceModel) usesWriteOnlyCredentials() bool {
+	switch data.getAuthMethod() {
+	case AUTH_METHOD_PASSWORD:
+		return data.AzureDevopsPassword.IsNull() && data.AzureDevopsPasswordFile.IsNull() && data.AzureDevopsPasswordCommand.IsNull()
+	case AUTH_METHOD_CLIENT_SECRET:
+		return data.AppClientSecret.IsNull() && data.AppClientSecretFile.IsNull() && data.AppClientSecretCommand.IsNull()
+	default:
+		return false
+	}
+}
 
-		tflog.Info(ctx, "Workaround : Make app public while getting token")
-		cmd := exec.Command(appPublic[0], appPublic[1:]...)
-		_, err := cmd.Output()
+// patClient returns the client managing PATs through the configured endpoint and auth method.
+func (r *AzurePatResource) patClient(ctx context.Context, auth *AzureAuthModel) (azdo.PatClient, error) {
+	tokens, err := auth.tokenProvider(ctx, r.client, r.cloud, r.tokens)
+	if err != nil {
+		return nil, err
+	}
+	return r.newPatClient(auth.AzureDevopsPatEndpoint.ValueString(), tokens), nil
+}
 
-		if err != nil {
-			tflog.Info(ctx, err.Error())
+// tokenAdminClient returns the client reading the PAT policy of the organization of the PAT endpoint.
+func (r *AzurePatResource) tokenAdminClient(ctx context.Context, auth *AzureAuthModel) (azdo.TokenAdminClient, error) {
+	tokens, err := auth.tokenProvider(ctx, r.client, r.cloud, r.tokens)
+	if err != nil {
+		return nil, err
+	}
+	return r.newTokenAdminClient(azdo.TokenAdminEndpoint(auth.AzureDevopsPatEndpoint.ValueString()), tokens), nil
+}
+
+// validityDaysChanged replaces the PAT when 'validity_days' changes, except from state written
+// before it existed as the PAT was then created with its default.
+func validityDaysChanged(ctx context.Context, req planmodifier.Int64Request, resp *int64planmodifier.RequiresReplaceIfFuncResponse) {
+	resp.RequiresReplace = !req.StateValue.IsNull()
+}
+
+// replaceUnlessImported replaces the PAT when the attribute changes, except when the state only holds what
+// ImportState sets: the attributes are then checked by checkImported and recorded by the next apply.
+func replaceUnlessImported(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+	var patName types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("pat_name"), &patName)...)
+	resp.RequiresReplace = !patName.IsNull()
+}
+
+// unadoptedPats returns the active PATs still marked as being issued for markerKey, the latest first.
+// As Create renames the PATs it saves into state, they were issued by an earlier Create whose state
+// was never saved, or are being issued by another resource with the same attributes.
+func unadoptedPats(ctx context.Context, client azdo.PatClient, markerKey string) ([]azdo.PatToken, error) {
+	patTokens, err := client.List(ctx, azdo.ListPatsOptions{DisplayFilterOption: "active"})
+	if err != nil {
+		return nil, err
+	}
+	unadopted := []azdo.PatToken{}
+	for _, patToken := range patTokens {
+		if _, key, ok := azdo.ParsePatMarker(patToken.DisplayName); ok && key != "" && key == markerKey {
+			unadopted = append(unadopted, patToken)
 		}
+	}
+	sort.SliceStable(unadopted, func(i, j int) bool {
+		return unadopted[i].ValidFrom.After(unadopted[j].ValidFrom)
+	})
+	return unadopted, nil
+}
+
+// patMarkerKey identifies the PATs being created for the attributes of data forcing a new PAT.
+func (data *AzurePatResourceModel) patMarkerKey() string {
+	return azdo.PatMarkerKey(
+		data.PatName.ValueString(),
+		data.AzureDevopsPatScopes.ValueString(),
+		data.RotateWhenChanged.ValueString(),
+		data.PgpKey.ValueString(),
+		strconv.FormatInt(data.ValidityDays.ValueInt64(), 10),
+	)
+}
+
+// renamePat renames patToken to displayName, keeping its scopes and validity.
+func renamePat(ctx context.Context, client azdo.PatClient, patToken *azdo.PatToken, displayName string) error {
+	_, err := client.Update(ctx, azdo.UpdatePatRequest{
+		AuthorizationID: patToken.AuthorizationID,
+		DisplayName:     displayName,
+		Scope:           patToken.Scope,
+		ValidTo:         patToken.ValidTo,
+	})
+	return err
+}
+
+func (r *AzurePatResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		authConfigValidator{},
+	}
+}
+
+func (r *AzurePatResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data AzurePatResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 
-		if switchPrivatePublicWait == 0 {
-			switchPrivatePublicWait = SWITCH_PRIVATE_PUBLIC_DEFAULT_WAIT
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// keybase references are read from the provider directory, only known once configured
+	if !data.PgpKey.IsNull() && !data.PgpKey.IsUnknown() && !strings.HasPrefix(data.PgpKey.ValueString(), pgp.KEYBASE_PREFIX) {
+		if _, err := pgp.ParsePublicKey(data.PgpKey.ValueString(), ""); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("pgp_key"), "Invalid PGP Key", fmt.Sprintf("Could not read pgp_key: %v", err))
 		}
-		tflog.Info(ctx, fmt.Sprintf("Workaround : sleep %d sec to take effect", switchPrivatePublicWait))
-		time.Sleep(time.Duration(switchPrivatePublicWait) * time.Second)
+	}
 
+	// Reject unreadable or expired certificates at plan time rather than during apply
+	if data.AppClientCertificate.IsNull() || data.AppClientCertificate.IsUnknown() || data.AppClientCertificatePwd.IsUnknown() {
+		return
 	}
+	if _, _, err := azdo.LoadClientCertificate(data.AppClientCertificate.ValueString(), data.AppClientCertificatePwd.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("app_client_certificate"), "Invalid Certificate", fmt.Sprintf("Could not load app_client_certificate: %v", err))
+	}
+}
 
-	// Now get token using public app
-	app, err := public.New(appID, public.WithAuthority(authority))
+// ModifyPlan warns about PATs expiring soon, and verifies the credentials with the provider 'verify_credentials_on_plan',
+// so that broken credentials fail the plan before other resources of the graph are changed.
+func (r *AzurePatResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	r.checkImported(ctx, req, resp)
+	r.warnExpiry(ctx, req, resp)
+	r.checkPolicy(ctx, req, resp)
+	if resp.Diagnostics.HasError() || !r.verifyCredentialsOnPlan {
+		return
+	}
+
+	var config *AzurePatResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
+	// Credentials from other resources are only known during apply
+	auth := config.withWriteOnly(config)
+	if auth.hasUnknown() {
+		tflog.Info(ctx, "Plan: credentials are not known yet, skip their verification")
+		return
+	}
+	// The workaround would switch the app registration public on every plan
+	if auth.SwitchPrivatePublic.ValueBool() {
+		tflog.Info(ctx, "Plan: az_cli_switch_private_app_public is set, skip the credentials verification")
+		return
+	}
+
+	if err := auth.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+		resp.Diagnostics.AddError("Invalid Credentials", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+		return
+	}
+	tokens, err := auth.tokenProvider(ctx, r.client, r.cloud, r.tokens)
+	if err != nil {
+		addAuthError(&resp.Diagnostics, "Could not verify credentials: %v", err)
+		return
+	}
+	organizationURL := auth.organizationURL(r.cloud)
+	connectionData, err := azdo.GetConnectionData(ctx, r.client, organizationURL, tokens, r.cloud.AzureDevopsScope())
 	if err != nil {
-		return "", err
+		resp.Diagnostics.AddError("Invalid Credentials", fmt.Sprintf("Could not authenticate to %s, got error %v", organizationURL, err))
+		return
+	}
+	tflog.Info(ctx, fmt.Sprintf("Plan: credentials verified, authenticated as %s", connectionData.AuthenticatedUser.ProviderDisplayName))
+}
+
+// checkImported compares an imported PAT with the configuration on the first plan after the import, as its
+// name and scopes can not be read back without the credentials of the configuration. A PAT matching it is kept,
+// the plan fails otherwise.
+func (r *AzurePatResource) checkImported(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var data, config, state *AzurePatResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() || !state.PatName.IsNull() || data.PatID.IsUnknown() {
+		return
+	}
+	auth := config.withWriteOnly(config)
+	if auth.hasUnknown() || data.PatName.IsUnknown() || data.AzureDevopsPatScopes.IsUnknown() {
+		tflog.Info(ctx, "Plan: credentials are not known yet, skip the imported PAT check")
+		return
+	}
+	// The workaround would switch the app registration public on every plan, the next apply checks the PAT instead
+	if auth.SwitchPrivatePublic.ValueBool() {
+		tflog.Info(ctx, "Plan: az_cli_switch_private_app_public is set, skip the imported PAT check")
+		return
 	}
-	result, err := app.AcquireTokenByUsernamePassword(context.Background(), []string{apiScope}, azureUser, azurePassword)
 
+	if err := auth.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+		return
+	}
+	client, err := r.patClient(ctx, auth)
 	if err != nil {
-		return "", err
+		addAuthError(&resp.Diagnostics, "Could not get token to read the imported PAT: %v", err)
+		return
+	}
+	patToken, err := client.Get(ctx, state.PatID.ValueString())
+	if azdo.IsPatNotFound(err) {
+		resp.Diagnostics.AddAttributeError(path.Root("pat_id"), "Imported PAT Not Found", fmt.Sprintf("PAT %s is revoked or not owned by the identity of the configuration, import an active PAT of this identity or remove it from the state with 'terraform state rm'", state.PatID.ValueString()))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not read imported PAT %s, got error %v", state.PatID.ValueString(), err))
+		return
 	}
 
-	// We got the token , make it back to private
-	if switchPrivatePublic {
-		appPrivate := append(azCmd, "false")
-		tflog.Info(ctx, "Workaround : Make app back to private now we have the token")
-		cmd := exec.Command(appPrivate[0], appPrivate[1:]...)
-		_, err = cmd.Output()
+	checkImportedMatches(patToken, data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Info(ctx, fmt.Sprintf("Plan: imported PAT %s matches the configuration, keep it", state.PatID.ValueString()))
+}
 
-		if err != nil {
-			tflog.Info(ctx, err.Error())
+// checkImportedMatches fails when the imported patToken is not named and scoped as data.
+// Replacing the PAT could not revoke it, as its state holds no credentials yet.
+func checkImportedMatches(patToken *azdo.PatToken, data *AzurePatResourceModel, diags *diag.Diagnostics) {
+	imported := &AzurePatResourceModel{}
+	imported.setImported(patToken)
+	if imported.PatName.Equal(data.PatName) && sameScopes(imported.AzureDevopsPatScopes.ValueString(), data.AzureDevopsPatScopes.ValueString()) {
+		return
+	}
+	diags.AddAttributeError(
+		path.Root("pat_name"),
+		"Imported PAT Mismatch",
+		fmt.Sprintf("Imported PAT %s is named %q with scopes %q, set pat_name and azure_devops_pat_scopes to match it, or remove it from the state with 'terraform state rm' and revoke it", patToken.AuthorizationID, imported.PatName.ValueString(), imported.AzureDevopsPatScopes.ValueString()),
+	)
+}
+
+// sameScopes tells whether the whitespace separated scopes a and b are the same, whatever their order.
+func sameScopes(a, b string) bool {
+	scopesA, scopesB := strings.Fields(a), strings.Fields(b)
+	sort.Strings(scopesA)
+	sort.Strings(scopesB)
+	return slices.Equal(scopesA, scopesB)
+}
+
+// setImported records into data the name and scopes of patToken that ImportState could not set.
+func (data *AzurePatResourceModel) setImported(patToken *azdo.PatToken) {
+	if data.PatName.IsNull() {
+		name, _, ok := azdo.ParsePatMarker(patToken.DisplayName)
+		if !ok {
+			name = patToken.DisplayName
 		}
+		data.PatName = types.StringValue(name)
+	}
+	if data.AzureDevopsPatScopes.IsNull() {
+		data.AzureDevopsPatScopes = types.StringValue(patToken.Scope)
 	}
-	return result.AccessToken, nil
 }
 
-func (r *AzurePatResource) getConfidentialAdToken(ctx context.Context, appID string, appSecret string, authority string, apiScope string) (string, error) {
+// warnExpiry warns when the PAT kept by the plan expires within 'expiry_warning_days'.
+func (r *AzurePatResource) warnExpiry(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var data, state *AzurePatResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// A replaced PAT gets a new validity
+	if data.replacesPat(state) || state.ValidTo.IsNull() || data.ExpiryWarningDays.ValueInt64() == 0 {
+		return
+	}
 
-	tflog.Info(ctx, "getConfidentialAdToken, first use app secret to connect to app")
-	// Initializing the client credential
-	cred, err := confidential.NewCredFromSecret(appSecret)
+	validTo, err := time.Parse(time.RFC3339, state.ValidTo.ValueString())
 	if err != nil {
-		return "", err
+		tflog.Info(ctx, fmt.Sprintf("Plan: could not read valid_to %q, %v", state.ValidTo.ValueString(), err))
+		return
+	}
+	days := daysUntil(validTo, time.Now())
+	switch {
+	case days < 0:
+		resp.Diagnostics.AddWarning(
+			"PAT Expired",
+			fmt.Sprintf("PAT %q (%s) expired on %s. Rotate it, e.g. by changing 'rotate_when_changed'", state.PatName.ValueString(), state.PatID.ValueString(), state.ValidTo.ValueString()),
+		)
+	case days < data.ExpiryWarningDays.ValueInt64():
+		resp.Diagnostics.AddWarning(
+			"PAT Expiring Soon",
+			fmt.Sprintf("PAT %q (%s) expires on %s, in %d days. Rotate it, e.g. by changing 'rotate_when_changed'", state.PatName.ValueString(), state.PatID.ValueString(), state.ValidTo.ValueString(), days),
+		)
+	}
+}
+
+// replacesPat tells whether the plan data replaces the PAT of state. ModifyPlan does not see the
+// replacements required by the attribute plan modifiers, so the attributes requiring one are compared.
+// A change of identity leaves pat_id unknown.
+func (data *AzurePatResourceModel) replacesPat(state *AzurePatResourceModel) bool {
+	if data.PatID.IsUnknown() || !data.AzureDevopsOrganization.Equal(state.AzureDevopsOrganization) {
+		return true
+	}
+	// The first apply after an import records these attributes without a replacement
+	if !state.PatName.IsNull() && (!data.PatName.Equal(state.PatName) ||
+		!data.AzureDevopsPatScopes.Equal(state.AzureDevopsPatScopes) ||
+		!data.RotateWhenChanged.Equal(state.RotateWhenChanged) ||
+		!data.PgpKey.Equal(state.PgpKey)) {
+		return true
+	}
+	return !state.ValidityDays.IsNull() && !data.ValidityDays.Equal(state.ValidityDays)
+}
+
+// checkPolicy checks a new PAT against the organization PAT policy with 'on_policy_violation',
+// so that it fails the plan rather than the creation during apply.
+func (r *AzurePatResource) checkPolicy(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var data, config *AzurePatResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Only new PATs are subject to the policy
+	if data.OnPolicyViolation.ValueString() == ON_POLICY_VIOLATION_IGNORE || !data.PatID.IsUnknown() {
+		return
+	}
+	// Computed auth attributes are unknown in the plan until resolved from the configuration
+	auth := config.withWriteOnly(config)
+	if auth.hasUnknown() || data.AzureDevopsPatScopes.IsUnknown() || data.ValidityDays.IsUnknown() {
+		tflog.Info(ctx, "Plan: PAT attributes are not known yet, skip the PAT policy check")
+		return
+	}
+	// The workaround would switch the app registration public on every plan
+	if auth.SwitchPrivatePublic.ValueBool() {
+		tflog.Info(ctx, "Plan: az_cli_switch_private_app_public is set, skip the PAT policy check")
+		return
 	}
-	confidentialClientApp, err := confidential.New(authority, appID, cred)
 
+	if err := auth.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+		return
+	}
+	client, err := r.tokenAdminClient(ctx, auth)
+	if err != nil {
+		addAuthError(&resp.Diagnostics, "Could not get token to read the PAT policy: %v", err)
+		return
+	}
+	policy, err := client.GetPatPolicy(ctx)
+	if azdo.IsPatPolicyUnavailable(err) {
+		resp.Diagnostics.AddWarning("PAT Policy Not Checked", fmt.Sprintf("The organization does not serve the PAT policy API, a preview, PAT %q is created without checking 'on_policy_violation', got error %v", data.PatName.ValueString(), err))
+		return
+	}
 	if err != nil {
-		return "", err
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not read the organization PAT policy, set 'on_policy_violation = \"ignore\"' to skip its checks, got error %v", err))
+		return
+	}
+
+	if err := policy.CheckScopes(data.AzureDevopsPatScopes.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("azure_devops_pat_scopes"), "PAT Policy Violation", fmt.Sprintf("PAT %q can not be created, %v", data.PatName.ValueString(), err))
+	}
+	validityDays := data.ValidityDays.ValueInt64()
+	if policy.MaxLifespanDays <= 0 || validityDays <= policy.MaxLifespanDays {
+		return
+	}
+	if data.OnPolicyViolation.ValueString() == ON_POLICY_VIOLATION_CLAMP {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("validity_days"),
+			"PAT Validity Clamped",
+			fmt.Sprintf("validity_days %d exceeds the %d days maximum lifespan of the organization PAT policy, PAT %q will be valid for %d days", validityDays, policy.MaxLifespanDays, data.PatName.ValueString(), policy.MaxLifespanDays),
+		)
+		return
+	}
+	resp.Diagnostics.AddAttributeError(
+		path.Root("validity_days"),
+		"PAT Policy Violation",
+		fmt.Sprintf("validity_days %d exceeds the %d days maximum lifespan of the organization PAT policy, lower it or set 'on_policy_violation = \"clamp\"'", validityDays, policy.MaxLifespanDays),
+	)
+}
+
+// validTo returns when a PAT created now for data expires, clamped with 'on_policy_violation = "clamp"'
+// to the maximum lifespan of the organization PAT policy.
+func (r *AzurePatResource) validTo(ctx context.Context, data *AzurePatResourceModel, auth *AzureAuthModel, now time.Time) (time.Time, error) {
+	validTo := now.AddDate(0, 0, int(data.ValidityDays.ValueInt64()))
+	if data.OnPolicyViolation.ValueString() != ON_POLICY_VIOLATION_CLAMP {
+		return validTo, nil
 	}
 
-	tflog.Info(ctx, "getConfidentialAdToken, second get token")
-	result, err := confidentialClientApp.AcquireTokenByCredential(ctx, []string{apiScope})
+	client, err := r.tokenAdminClient(ctx, auth)
 	if err != nil {
-		return "", err
+		return time.Time{}, err
 	}
+	policy, err := client.GetPatPolicy(ctx)
+	if azdo.IsPatPolicyUnavailable(err) {
+		tflog.Info(ctx, "Create: the organization does not serve the PAT policy API, validity not clamped")
+		return validTo, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not read the organization PAT policy: %w", err)
+	}
+	if maxValidTo, ok := policy.MaxValidTo(now); ok && validTo.After(maxValidTo) {
+		tflog.Info(ctx, fmt.Sprintf("Create: validity clamped to the %d days of the organization PAT policy", policy.MaxLifespanDays))
+		return maxValidTo, nil
+	}
+	return validTo, nil
+}
 
-	return result.AccessToken, nil
+// daysUntil returns the number of whole days from now to validTo, negative once past.
+func daysUntil(validTo time.Time, now time.Time) int64 {
+	return int64(math.Floor(validTo.Sub(now).Hours() / 24))
+}
 
+// setValidity records when patToken expires into data.
+func (data *AzurePatResourceModel) setValidity(patToken *azdo.PatToken) {
+	data.ValidTo = types.StringValue(patToken.ValidTo.UTC().Format(time.RFC3339))
+	data.DaysUntilExpiry = types.Int64Value(daysUntil(patToken.ValidTo, time.Now()))
 }
 
 func (r *AzurePatResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
@@ -253,134 +869,271 @@ func (r *AzurePatResource) Configure(ctx context.Context, req resource.Configure
 		return
 	}
 
-	client, ok := req.ProviderData.(*http.Client)
+	providerData, ok := req.ProviderData.(*HelloassoProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *http.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *HelloassoProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = providerData.Client
+	r.cloud = providerData.Cloud
+	r.pgpKeybaseDir = providerData.PgpKeybaseDir
+	r.tokens = providerData.Tokens
+	r.verifyCredentialsOnPlan = providerData.VerifyCredentialsOnPlan
 }
 
-func (r *AzurePatResource) deletePat(_ context.Context, patID string, azureDevopsPatEndpoint string, token string) error {
+func (r *AzurePatResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data, config *AzurePatResourceModel
 
-	client := &http.Client{}
-	delete_req, _ := http.NewRequest(http.MethodDelete, azureDevopsPatEndpoint+"?"+PAT_API_VERSION+"&authorizationId="+patID, nil)
-	delete_req.Header.Set("Authorization", "Bearer "+token)
-	res, err := client.Do(delete_req)
-	if err != nil {
-		return err
-	}
-	if res.StatusCode != 200 && res.StatusCode != 204 {
+	// Read Terraform plan data into the model, write-only attributes are only in config
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 
-		defer res.Body.Close()
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-		body, err := io.ReadAll(res.Body)
+	// Read the key before creating the PAT, not to leave an unusable PAT behind
+	var pgpKey *pgp.PublicKey
+	if !data.PgpKey.IsNull() {
+		var err error
+		pgpKey, err = pgp.ParsePublicKey(data.PgpKey.ValueString(), r.pgpKeybaseDir)
 		if err != nil {
-			return err
+			resp.Diagnostics.AddAttributeError(path.Root("pgp_key"), "Invalid PGP Key", fmt.Sprintf("Could not read pgp_key: %v", err))
+			return
 		}
-		return fmt.Errorf("DELETE API did not return 200 or 204 but %d, message %v", res.StatusCode, string(body))
 	}
-	return nil
-}
-
-func (r *AzurePatResource) createPat(_ context.Context, patName string, patScopes string, azureDevopsPatEndpoint string, token string) (*PatCreationResponse, error) {
 
-	now := time.Now()
-	expiration := now.AddDate(1, 0, 0)
-	postData := map[string]string{
-		"allOrgs":     "false",
-		"displayName": patName,
-		"scope":       patScopes,
-		"validTo":     expiration.Format(time.RFC3339),
+	if err := data.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+		return
 	}
-	json_data, err := json.Marshal(postData)
 
+	client, err := r.patClient(ctx, data.withWriteOnly(config))
 	if err != nil {
-		return nil, err
+		addAuthError(&resp.Diagnostics, "Could not get token for PAT creation: %v", err)
+		return
 	}
-	client := &http.Client{}
-	graph_req, _ := http.NewRequest(http.MethodPost, azureDevopsPatEndpoint+"?"+PAT_API_VERSION, bytes.NewBuffer(json_data))
-	graph_req.Header.Set("Authorization", "Bearer "+token)
-	graph_req.Header.Set("Content-Type", "application/json")
-	res, err := client.Do(graph_req)
+
+	// A PAT still marked as being created for the same attributes by a previous apply was never saved into state
+	markerKey := data.patMarkerKey()
+	unadopted, err := unadoptedPats(ctx, client, markerKey)
 	if err != nil {
-		return nil, err
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not list PATs created earlier, got error %v", err))
+		return
+	}
+	adopted := false
+	if len(unadopted) > 0 {
+		switch data.OnExisting.ValueString() {
+		case ON_EXISTING_ERROR:
+			resp.Diagnostics.AddError(
+				"Existing PAT",
+				fmt.Sprintf("PAT %s was created earlier with the same attributes but never saved into state, or is being created by another resource with the same attributes which then needs a distinct pat_name. Set 'on_existing = \"revoke\"' to revoke it and create a new PAT, or 'on_existing = \"adopt\"' to keep it without its secret", unadopted[0].AuthorizationID),
+			)
+			return
+		case ON_EXISTING_ADOPT:
+			patToken := unadopted[0]
+			unadopted = unadopted[1:]
+			adopted = true
+			tflog.Info(ctx, fmt.Sprintf("Create: adopt PAT %s created earlier", patToken.AuthorizationID))
+			if err := renamePat(ctx, client, &patToken, azdo.ManagedDisplayName(data.PatName.ValueString())); err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not adopt PAT %s created earlier, got error %v", patToken.AuthorizationID, err))
+				return
+			}
+			data.Pat = types.StringValue("")
+			data.PatID = types.StringValue(patToken.AuthorizationID)
+			data.setValidity(&patToken)
+			data.EncryptedPat = types.StringNull()
+			data.KeyFingerprint = types.StringNull()
+			resp.Diagnostics.AddWarning(
+				"PAT Adopted",
+				fmt.Sprintf("PAT %s created earlier for this resource was adopted, its secret can not be read back so 'pat' is empty. Change 'rotate_when_changed' to create a new PAT", patToken.AuthorizationID),
+			)
+		}
+		for _, patToken := range unadopted {
+			tflog.Info(ctx, fmt.Sprintf("Create: revoke PAT %s created earlier", patToken.AuthorizationID))
+			if err := client.Revoke(ctx, patToken.AuthorizationID); err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not revoke PAT %s created earlier, got error %v", patToken.AuthorizationID, err))
+				return
+			}
+		}
+	}
+	if adopted {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
 	}
 
-	patCreationResponse := &PatCreationResponse{}
-	err = json.NewDecoder(res.Body).Decode(patCreationResponse)
-
+	validTo, err := r.validTo(ctx, data, data.withWriteOnly(config), time.Now())
 	if err != nil {
-		return nil, err
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not clamp the PAT validity, got error %v", err))
+		return
 	}
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("Create PAT returned %d, error: %v", res.StatusCode, patCreationResponse.PatTokenError)
+	patToken, err := client.Create(ctx, azdo.CreatePatRequest{
+		DisplayName: azdo.MarkedDisplayName(data.PatName.ValueString(), markerKey),
+		Scope:       data.AzureDevopsPatScopes.ValueString(),
+		ValidTo:     validTo,
+	})
+
+	if azdo.IsPatPolicyViolation(err) {
+		resp.Diagnostics.AddError("PAT Policy Violation", fmt.Sprintf("Could not create PAT, the organization PAT policy forbids it, see the 'helloasso_azure_devops_pat_policy' data source and 'on_policy_violation', got error %v", err))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not get token, check app registration Public status, got error %v", err))
+		return
 	}
 
-	return patCreationResponse, nil
+	if timeout := data.WaitUntilUsable.ValueInt64(); timeout > 0 {
+		checkURL := azdo.PatCheckURL(data.organizationURL(r.cloud), data.AzureDevopsPatScopes.ValueString())
+		tflog.Info(ctx, fmt.Sprintf("Create: wait for PAT %s to be usable on %s", patToken.AuthorizationID, checkURL))
+		if err := azdo.WaitPatUsable(ctx, r.client, checkURL, patToken.Token, time.Duration(timeout)*time.Second, azdo.PAT_CHECK_POLL_INTERVAL); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("PAT %s is not usable, got error %v", patToken.AuthorizationID, err))
+			if err := client.Revoke(ctx, patToken.AuthorizationID); err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not revoke unusable PAT %s, got error %v", patToken.AuthorizationID, err))
+			}
+			return
+		}
+	}
+
+	data.Pat = types.StringValue(patToken.Token)
+	data.PatID = types.StringValue(patToken.AuthorizationID)
+	data.setValidity(patToken)
+	data.EncryptedPat = types.StringNull()
+	data.KeyFingerprint = types.StringNull()
 
+	if pgpKey != nil {
+		encrypted, err := pgpKey.Encrypt(patToken.Token)
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not encrypt PAT %s, got error %v", patToken.AuthorizationID, err))
+			if err := client.Revoke(ctx, patToken.AuthorizationID); err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not revoke unencrypted PAT %s, got error %v", patToken.AuthorizationID, err))
+			}
+			return
+		}
+		data.Pat = types.StringValue("")
+		data.EncryptedPat = types.StringValue(encrypted)
+		data.KeyFingerprint = types.StringValue(pgpKey.Fingerprint())
+	}
+
+	// Once saved into state, the PAT must not be taken for one whose creation crashed, e.g. when kept by 'revoke_on_destroy = false'
+	if err := renamePat(ctx, client, patToken, azdo.ManagedDisplayName(data.PatName.ValueString())); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not mark PAT %s as saved into state, got error %v", patToken.AuthorizationID, err))
+		if err := client.Revoke(ctx, patToken.AuthorizationID); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not revoke unmarked PAT %s, got error %v", patToken.AuthorizationID, err))
+		}
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-func (r *AzurePatResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+func (r *AzurePatResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var data *AzurePatResourceModel
 
-	// Read Terraform plan data into the model
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var accessToken string
-	var err error
-	if data.IsAppRegistrationPublic.ValueBool() {
-		accessToken, err = r.getPublicAdToken(ctx, data.AppClientID.ValueString(), data.AzureDevopsUser.ValueString(), data.AzureDevopsPassword.ValueString(), data.Authority.ValueString(), AZ_SCOPE_DEVOPS, data.SwitchPrivatePublic.ValueBool(), data.SwitchPrivatePublicWait.ValueInt64())
-	} else {
-		if data.AppClientSecret.IsNull() || data.AppClientSecret.IsUnknown() {
-			resp.Diagnostics.AddError("Client Error", "PAT creation: You need to set app_client_secret if is_app_registration_public=false")
-			return
-		}
-		accessToken, err = r.getConfidentialAdToken(ctx, data.AppClientID.ValueString(), data.AppClientSecret.ValueString(), data.Authority.ValueString(), AZ_SCOPE_DEVOPS)
+	// The az CLI workaround would switch the app public on every refresh
+	if data.SwitchPrivatePublic.ValueBool() {
+		tflog.Info(ctx, "Read state: az_cli_switch_private_app_public is set, skip PAT refresh")
+		return
 	}
 
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not get token for PAT creation: %v", err))
+	if err := data.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+		tflog.Info(ctx, fmt.Sprintf("Read state: skip PAT refresh, %v", err))
 		return
 	}
 
-	patCreationResponse, err := r.createPat(ctx, data.PatName.ValueString(), data.AzureDevopsPatScopes.ValueString(), data.AzureDevopsPatEndpoint.ValueString(), accessToken)
-
+	client, err := r.patClient(ctx, &data.AzureAuthModel)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not get token, check app registration Public status, got error %v", err))
+		// Imported PATs have no credentials in state until the next apply
+		tflog.Info(ctx, fmt.Sprintf("Read state: skip PAT refresh, %v", err))
 		return
 	}
 
-	data.Pat = types.StringValue(patCreationResponse.PatToken.Token)
-	data.PatID = types.StringValue(patCreationResponse.PatToken.AuthorizationId)
+	patToken, err := client.Get(ctx, data.PatID.ValueString())
+	if azdo.IsPatNotFound(err) {
+		tflog.Info(ctx, fmt.Sprintf("Read state: PAT %s was revoked outside of terraform", data.PatID.ValueString()))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not read PAT, got error %v", err))
+		return
+	}
+	data.setImported(patToken)
+	data.setValidity(patToken)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-func (r *AzurePatResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-
-}
-
 func (r *AzurePatResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data *AzurePatResourceModel
+	var data, config, state *AzurePatResourceModel
 
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	// A new version of write-only credentials is checked against the PAT API
+	if !data.AzureDevopsPasswordWOVersion.Equal(state.AzureDevopsPasswordWOVersion) || !data.AppClientSecretWOVersion.Equal(state.AppClientSecretWOVersion) {
+		if err := data.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+			return
+		}
+
+		client, err := r.patClient(ctx, data.withWriteOnly(config))
+		if err != nil {
+			addAuthError(&resp.Diagnostics, "Could not authenticate with the new write-only credentials, got error %v", err)
+			return
+		}
+		if _, err := client.Get(ctx, data.PatID.ValueString()); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not authenticate with the new write-only credentials, got error %v", err))
+			return
+		}
+	}
+
+	// The first apply after an import records the validity of the imported PAT, checked by the plan unless skipped
+	if state.PatName.IsNull() {
+		if err := data.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+			return
+		}
+		client, err := r.patClient(ctx, data.withWriteOnly(config))
+		if err != nil {
+			addAuthError(&resp.Diagnostics, "Could not get token to read the imported PAT, got error %v", err)
+			return
+		}
+		patToken, err := client.Get(ctx, data.PatID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not read imported PAT %s, got error %v", data.PatID.ValueString(), err))
+			return
+		}
+		checkImportedMatches(patToken, data, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		data.setValidity(patToken)
+	}
+
+	// The PAT is not recreated, keep its outputs even when null in state
+	data.Pat = state.Pat
+	data.EncryptedPat = state.EncryptedPat
+	data.KeyFingerprint = state.KeyFingerprint
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -400,24 +1153,40 @@ func (r *AzurePatResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	} else {
 
-		var accessToken string
-		var err error
-		if data.IsAppRegistrationPublic.ValueBool() {
-			accessToken, err = r.getPublicAdToken(ctx, data.AppClientID.ValueString(), data.AzureDevopsUser.ValueString(), data.AzureDevopsPassword.ValueString(), data.Authority.ValueString(), AZ_SCOPE_DEVOPS, data.SwitchPrivatePublic.ValueBool(), data.SwitchPrivatePublicWait.ValueInt64())
-		} else {
-			if data.AppClientSecret.IsNull() || data.AppClientSecret.IsUnknown() {
-				resp.Diagnostics.AddError("Client Error", "PAT deletion: You need to set app_client_secret if is_app_registration_public=false")
+		keep := !data.RevokeOnDestroy.IsNull() && !data.RevokeOnDestroy.ValueBool()
+		if data.usesWriteOnlyCredentials() {
+			if keep {
+				tflog.Info(ctx, fmt.Sprintf("Delete state: revoke_on_destroy is false, keep PAT %s with its marker, the write-only credentials are not in state", data.PatID.ValueString()))
 				return
 			}
-			accessToken, err = r.getConfidentialAdToken(ctx, data.AppClientID.ValueString(), data.AppClientSecret.ValueString(), data.Authority.ValueString(), AZ_SCOPE_DEVOPS)
+			resp.Diagnostics.AddWarning(
+				"PAT Not Revoked",
+				fmt.Sprintf("PAT %s can not be revoked: its credentials are not in state, as write-only attributes are not kept after apply. It stays valid until it expires or is revoked from Azure Devops, e.g. with 'helloasso_azure_pat_reaper'", data.PatID.ValueString()),
+			)
+			return
+		}
+
+		if keep {
+			r.unmark(ctx, data, &resp.Diagnostics)
+			return
+		}
+
+		if err := data.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not resolve Azure Devops organization: %v", err))
+			return
 		}
 
+		client, err := r.patClient(ctx, &data.AzureAuthModel)
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not get token for PAT deletion: %v", err))
+			addAuthError(&resp.Diagnostics, "Could not get token for PAT deletion: %v", err)
 			return
 		}
 
-		err = r.deletePat(ctx, data.PatID.ValueString(), data.AzureDevopsPatEndpoint.ValueString(), accessToken)
+		err = client.Revoke(ctx, data.PatID.ValueString())
+		if azdo.IsPatNotFound(err) {
+			tflog.Info(ctx, fmt.Sprintf("Delete state: PAT %s was already revoked or expired", data.PatID.ValueString()))
+			return
+		}
 		if err != nil {
 			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Could not delete PAT (maybe check app registration public status) err: %v", err))
 			return
@@ -425,6 +1194,53 @@ func (r *AzurePatResource) Delete(ctx context.Context, req resource.DeleteReques
 	}
 }
 
+// unmark removes the marker from the name of the PAT kept on destroy by 'revoke_on_destroy = false', as it is
+// no longer managed by terraform, e.g. by 'helloasso_azure_pat_reaper' with 'managed_only'. The PAT is kept
+// whatever happens, failures only warn.
+func (r *AzurePatResource) unmark(ctx context.Context, data *AzurePatResourceModel, diags *diag.Diagnostics) {
+	patID := data.PatID.ValueString()
+	tflog.Info(ctx, fmt.Sprintf("Delete state: revoke_on_destroy is false, keep PAT %s", patID))
+
+	if err := data.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+		diags.AddWarning("PAT Marker Not Removed", fmt.Sprintf("Could not resolve Azure Devops organization to rename kept PAT %s: %v", patID, err))
+		return
+	}
+	client, err := r.patClient(ctx, &data.AzureAuthModel)
+	if err != nil {
+		diags.AddWarning("PAT Marker Not Removed", fmt.Sprintf("Could not get token to rename kept PAT %s: %v", patID, err))
+		return
+	}
+	patToken, err := client.Get(ctx, patID)
+	if azdo.IsPatNotFound(err) {
+		tflog.Info(ctx, fmt.Sprintf("Delete state: PAT %s was already revoked or expired", patID))
+		return
+	}
+	if err == nil {
+		name, _, _ := azdo.ParsePatMarker(patToken.DisplayName)
+		err = renamePat(ctx, client, patToken, name)
+	}
+	if err != nil {
+		diags.AddWarning("PAT Marker Not Removed", fmt.Sprintf("Could not remove the marker from the name of kept PAT %s, got error %v", patID, err))
+	}
+}
+
 func (r *AzurePatResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	// The PAT secret can not be read back, only its ID and organization are imported. Its name and
+	// scopes are checked against the configuration by the next plan, see checkImported
+	organization, patID, found := strings.Cut(req.ID, "/")
+	if !found {
+		organization, patID = "", req.ID
+	}
+	if patID == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: <azure_devops_organization>/<pat_id> or <pat_id>. Got: %q", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("pat_id"), patID)...)
+	if organization != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("azure_devops_organization"), organization)...)
+	}
 }
@@ -0,0 +1,276 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &AzurePatReaperResource{}
+var _ resource.ResourceWithValidateConfig = &AzurePatReaperResource{}
+var _ resource.ResourceWithConfigValidators = &AzurePatReaperResource{}
+var _ resource.ResourceWithModifyPlan = &AzurePatReaperResource{}
+
+func NewAzurePatReaperResource() resource.Resource {
+	r := &AzurePatReaperResource{
+		cloud: azdo.CLOUDS[azdo.CLOUD_PUBLIC],
+	}
+	r.newPatClient = func(endpoint string, tokens azdo.TokenProvider) azdo.PatClient {
+		return azdo.NewPatClient(r.client, endpoint, tokens, r.cloud.AzureDevopsScope())
+	}
+	return r
+}
+
+// AzurePatReaperResource revokes the stale PATs of an identity.
+type AzurePatReaperResource struct {
+	client *http.Client
+	cloud  azdo.Cloud
+	tokens *azdo.TokenCache
+	// newPatClient builds the client of the PAT API, tests swap it for a fake
+	newPatClient func(endpoint string, tokens azdo.TokenProvider) azdo.PatClient
+}
+
+// AzurePatReaperResourceModel describes the resource data model.
+type AzurePatReaperResourceModel struct {
+	AzureAuthModel
+	NamePrefix    types.String `tfsdk:"name_prefix"`
+	ManagedOnly   types.Bool   `tfsdk:"managed_only"`
+	KeepPatIDs    types.Set    `tfsdk:"keep_pat_ids"`
+	DryRun        types.Bool   `tfsdk:"dry_run"`
+	RevokedPatIDs types.Set    `tfsdk:"revoked_pat_ids"`
+}
+
+func (r *AzurePatReaperResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_pat_reaper"
+}
+
+func (r *AzurePatReaperResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := resourceAuthAttributes(ctx)
+	attributes["name_prefix"] = schema.StringAttribute{
+		MarkdownDescription: "Only revoke the PATs whose name starts with this prefix",
+		Optional:            true,
+	}
+	attributes["managed_only"] = schema.BoolAttribute{
+		MarkdownDescription: "Only revoke the PATs created by 'helloasso_azure_pat', whose name ends with its '[tf-helloasso]' or '[tf-helloasso:<key>]' marker, removed from the PATs kept with 'revoke_on_destroy = false' (default: false)",
+		Optional:            true,
+		Computed:            true,
+		Default:             booldefault.StaticBool(false),
+	}
+	attributes["keep_pat_ids"] = schema.SetAttribute{
+		MarkdownDescription: "IDs of the matching PATs to keep, e.g. '[helloasso_azure_pat.gitops.pat_id]'",
+		ElementType:         types.StringType,
+		Optional:            true,
+	}
+	attributes["dry_run"] = schema.BoolAttribute{
+		MarkdownDescription: "Only list in 'revoked_pat_ids' the PATs that would be revoked (default: false)",
+		Optional:            true,
+		Computed:            true,
+		Default:             booldefault.StaticBool(false),
+	}
+	attributes["revoked_pat_ids"] = schema.SetAttribute{
+		MarkdownDescription: "IDs of the PATs revoked by the last apply, or that would be with 'dry_run = true'",
+		ElementType:         types.StringType,
+		Computed:            true,
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Revokes the active PATs of an identity matching 'name_prefix' or 'managed_only', except 'keep_pat_ids'. The plan shows an update whenever there are PATs to revoke, nothing is revoked on destroy. With 'az_cli_switch_private_app_public', or credentials only known during apply, the PATs are not listed during plan and only revoked when the configuration changes",
+		Attributes:          attributes,
+	}
+}
+
+func (r *AzurePatReaperResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		authConfigValidator{},
+	}
+}
+
+func (r *AzurePatReaperResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data AzurePatReaperResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() || data.NamePrefix.IsUnknown() || data.ManagedOnly.IsUnknown() {
+		return
+	}
+
+	// Without filter, every PAT of the identity would be revoked
+	if data.NamePrefix.ValueString() == "" && !data.ManagedOnly.ValueBool() {
+		resp.Diagnostics.AddAttributeError(path.Root("name_prefix"), "Missing Attribute", "One of name_prefix or 'managed_only = true' is required")
+	}
+}
+
+// ModifyPlan plans an update when PATs are to be revoked, so that they are on every apply
+// rather than only when the configuration changes.
+func (r *AzurePatReaperResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var data, config, state *AzurePatReaperResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Any other change already plans an update
+	if data.RevokedPatIDs.IsUnknown() {
+		return
+	}
+	// Credentials from other resources are only known during apply
+	if config.hasUnknown() {
+		tflog.Info(ctx, "Plan: credentials are not known yet, skip listing the PATs to revoke")
+		return
+	}
+	// The workaround would switch the app registration public on every plan
+	if config.SwitchPrivatePublic.ValueBool() {
+		tflog.Info(ctx, "Plan: az_cli_switch_private_app_public is set, skip listing the PATs to revoke")
+		return
+	}
+
+	// Computed auth attributes are unknown in the plan until resolved from the configuration again
+	if data.hasUnknown() {
+		data.AzureAuthModel = config.AzureAuthModel
+	}
+	patIDs, err := r.reap(ctx, data, true)
+	if err != nil {
+		addAuthError(&resp.Diagnostics, "Could not list PATs to revoke, got error %v", err)
+		return
+	}
+	plannedPatIDs, diags := types.SetValueFrom(ctx, types.StringType, patIDs)
+	resp.Diagnostics.Append(diags...)
+	if (data.DryRun.ValueBool() && !plannedPatIDs.Equal(state.RevokedPatIDs)) || (!data.DryRun.ValueBool() && len(patIDs) > 0) {
+		tflog.Info(ctx, fmt.Sprintf("Plan: PATs %s are to be revoked", strings.Join(patIDs, ", ")))
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("revoked_pat_ids"), types.SetUnknown(types.StringType))...)
+	}
+}
+
+func (r *AzurePatReaperResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*HelloassoProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *HelloassoProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = providerData.Client
+	r.cloud = providerData.Cloud
+	r.tokens = providerData.Tokens
+}
+
+// reap revokes the matching PATs not to keep and returns their IDs, dryRun only lists them.
+func (r *AzurePatReaperResource) reap(ctx context.Context, data *AzurePatReaperResourceModel, dryRun bool) ([]string, error) {
+	if err := data.resolveOrganization(ctx, r.client, r.cloud); err != nil {
+		return nil, fmt.Errorf("could not resolve Azure Devops organization: %w", err)
+	}
+	tokens, err := data.tokenProvider(ctx, r.client, r.cloud, r.tokens)
+	if err != nil {
+		return nil, err
+	}
+	client := r.newPatClient(data.AzureDevopsPatEndpoint.ValueString(), tokens)
+
+	keep := map[string]bool{}
+	for _, patID := range data.KeepPatIDs.Elements() {
+		keep[patID.(types.String).ValueString()] = true
+	}
+	patTokens, err := client.List(ctx, azdo.ListPatsOptions{DisplayFilterOption: "active"})
+	if err != nil {
+		return nil, err
+	}
+
+	patIDs := []string{}
+	for _, patToken := range patTokens {
+		_, _, managed := azdo.ParsePatMarker(patToken.DisplayName)
+		if keep[patToken.AuthorizationID] || !strings.HasPrefix(patToken.DisplayName, data.NamePrefix.ValueString()) || (data.ManagedOnly.ValueBool() && !managed) {
+			continue
+		}
+		if !dryRun {
+			tflog.Info(ctx, fmt.Sprintf("Reap: revoke PAT %s %q", patToken.AuthorizationID, patToken.DisplayName))
+			if err := client.Revoke(ctx, patToken.AuthorizationID); err != nil {
+				return nil, fmt.Errorf("could not revoke PAT %s: %w", patToken.AuthorizationID, err)
+			}
+		}
+		patIDs = append(patIDs, patToken.AuthorizationID)
+	}
+	sort.Strings(patIDs)
+	return patIDs, nil
+}
+
+// revoke reaps the PATs of data and records their IDs into it.
+func (r *AzurePatReaperResource) revoke(ctx context.Context, data *AzurePatReaperResourceModel, diags *diag.Diagnostics) {
+	patIDs, err := r.reap(ctx, data, data.DryRun.ValueBool())
+	if err != nil {
+		addAuthError(diags, "Could not revoke PATs, got error %v", err)
+		return
+	}
+	revokedPatIDs, setDiags := types.SetValueFrom(ctx, types.StringType, patIDs)
+	diags.Append(setDiags...)
+	data.RevokedPatIDs = revokedPatIDs
+}
+
+func (r *AzurePatReaperResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *AzurePatReaperResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.revoke(ctx, data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AzurePatReaperResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Nothing to refresh, the PATs to revoke are listed by ModifyPlan
+}
+
+func (r *AzurePatReaperResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data *AzurePatReaperResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.revoke(ctx, data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AzurePatReaperResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	tflog.Info(ctx, "Delete state: revoked PATs stay revoked, nothing to delete")
+}
@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	resourcetest "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdotest"
+)
+
+func testAccAzurePatReaperConfig(server *azdotest.Server, filter string) string {
+	return testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = "vso.code vso.packaging"
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = "usersuperpassword"
+}
+
+resource "helloasso_azure_pat_reaper" "test" {
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = "usersuperpassword"
+  keep_pat_ids              = [helloasso_azure_pat.test.pat_id]
+  %[4]s
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser, filter)
+}
+
+// testAccCheckPatsActive checks which PATs of the fake server are active.
+func testAccCheckPatsActive(server *azdotest.Server, active map[string]bool) resourcetest.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, pat := range server.Pats() {
+			if expected, ok := active[pat.DisplayName]; ok && expected != pat.Active() {
+				return fmt.Errorf("expected PAT %q active=%v", pat.DisplayName, expected)
+			}
+		}
+		return nil
+	}
+}
+
+func TestAccAzurePatReaperResource(t *testing.T) {
+	server := newTestAccServer(t, true)
+	stale := server.AddPat(testAccUserID, "gitops-old", "vso.code")
+	managed := server.AddPat(testAccUserID, azdo.MarkedDisplayName("gitops-lost", "0123456789ab"), "vso.code")
+	server.AddPat(testAccUserID, "laptop", "vso.code")
+	var leaked azdotest.Pat
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		Steps: []resourcetest.TestStep{
+			// Without filter every PAT would be revoked
+			{
+				Config:      testAccAzurePatReaperConfig(server, ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`One\s+of\s+name_prefix\s+or\s+'managed_only\s+=\s+true'\s+is\s+required`),
+			},
+			{
+				Config: testAccAzurePatReaperConfig(server, `
+  name_prefix = "gitops"
+  dry_run     = true`),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckResourceAttr("helloasso_azure_pat_reaper.test", "revoked_pat_ids.#", "2"),
+					resourcetest.TestCheckTypeSetElemAttr("helloasso_azure_pat_reaper.test", "revoked_pat_ids.*", stale.AuthorizationID),
+					resourcetest.TestCheckTypeSetElemAttr("helloasso_azure_pat_reaper.test", "revoked_pat_ids.*", managed.AuthorizationID),
+					testAccCheckPatsActive(server, map[string]bool{"gitops-old": true, managed.DisplayName: true, "laptop": true}),
+				),
+			},
+			{
+				Config: testAccAzurePatReaperConfig(server, `
+  name_prefix = "gitops"`),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckResourceAttr("helloasso_azure_pat_reaper.test", "revoked_pat_ids.#", "2"),
+					testAccCheckPatsActive(server, map[string]bool{"gitops-old": false, managed.DisplayName: false, "laptop": true}),
+				),
+			},
+			// PATs created since the last apply are revoked without configuration change
+			{
+				PreConfig: func() {
+					leaked = server.AddPat(testAccUserID, "gitops-leaked", "vso.code")
+				},
+				Config: testAccAzurePatReaperConfig(server, `
+  name_prefix = "gitops"`),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckResourceAttr("helloasso_azure_pat_reaper.test", "revoked_pat_ids.#", "1"),
+					func(s *terraform.State) error {
+						return resourcetest.TestCheckTypeSetElemAttr("helloasso_azure_pat_reaper.test", "revoked_pat_ids.*", leaked.AuthorizationID)(s)
+					},
+					testAccCheckPatsActive(server, map[string]bool{"gitops-leaked": false, "laptop": true}),
+				),
+			},
+			{
+				PreConfig: func() {
+					server.AddPat(testAccUserID, "ci", "vso.build")
+					server.AddPat(testAccUserID, azdo.MarkedDisplayName("ci", "ba9876543210"), "vso.build")
+				},
+				Config: testAccAzurePatReaperConfig(server, `
+  managed_only = true`),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckResourceAttr("helloasso_azure_pat_reaper.test", "revoked_pat_ids.#", "1"),
+					testAccCheckPatsActive(server, map[string]bool{"ci": true, azdo.MarkedDisplayName("ci", "ba9876543210"): false, "laptop": true}),
+					testAccCheckActivePatCount(server, 3),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckActivePatCount(server *azdotest.Server, count int) resourcetest.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if pats := server.ActivePats(); len(pats) != count {
+			return fmt.Errorf("expected %d active PATs, got %d", count, len(pats))
+		}
+		return nil
+	}
+}
+
+func TestAzurePatReaperResource_ModifyPlan(t *testing.T) {
+	ctx := context.Background()
+
+	for name, tc := range map[string]struct {
+		change       func(model *AzurePatReaperResourceModel)
+		expectListed bool
+	}{
+		"listed": {expectListed: true},
+		"unknown credentials": {change: func(model *AzurePatReaperResourceModel) {
+			model.AzureDevopsPassword = types.StringUnknown()
+		}},
+		"az cli switch": {change: func(model *AzurePatReaperResourceModel) {
+			model.SwitchPrivatePublic = types.BoolValue(true)
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := newFakePatClient()
+			if _, err := client.Create(ctx, azdo.CreatePatRequest{DisplayName: "gitops-old"}); err != nil {
+				t.Fatal(err)
+			}
+			listed := false
+			r := NewAzurePatReaperResource().(*AzurePatReaperResource)
+			r.newPatClient = func(_ string, _ azdo.TokenProvider) azdo.PatClient {
+				listed = true
+				return client
+			}
+			testData := newTestResourceData(t, r)
+
+			model := AzurePatReaperResourceModel{
+				AzureAuthModel: testAzurePatModel().AzureAuthModel,
+				NamePrefix:     types.StringValue("gitops"),
+				ManagedOnly:    types.BoolValue(false),
+				KeepPatIDs:     types.SetNull(types.StringType),
+				DryRun:         types.BoolValue(false),
+				RevokedPatIDs:  types.SetValueMust(types.StringType, []attr.Value{}),
+			}
+			model.AzureDevopsPatEndpoint = types.StringValue("https://vssps.dev.azure.com/myorganization/_apis/tokens/pats")
+			state := testData.state(&model)
+			if tc.change != nil {
+				tc.change(&model)
+			}
+			plan := testData.plan(&model)
+
+			resp := &resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: state, Config: testData.config(&model)}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+			if listed != tc.expectListed {
+				t.Errorf("expected PATs listed=%v, got %v", tc.expectListed, listed)
+			}
+			var revokedPatIDs types.Set
+			resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("revoked_pat_ids"), &revokedPatIDs)...)
+			if revokedPatIDs.IsUnknown() != tc.expectListed {
+				t.Errorf("expected an update planned=%v, got revoked_pat_ids %v", tc.expectListed, revokedPatIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,1378 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	resourcetest "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdo"
+	"github.com/hashicorp/terraform-provider-helloasso/internal/azdotest"
+)
+
+// fakePatClient keeps PATs in memory in place of the PAT API.
+type fakePatClient struct {
+	endpoint string
+	tokens   map[string]azdo.PatToken
+	revoked  []string
+}
+
+func newFakePatClient() *fakePatClient {
+	return &fakePatClient{tokens: map[string]azdo.PatToken{}}
+}
+
+func (f *fakePatClient) Create(_ context.Context, req azdo.CreatePatRequest) (*azdo.PatToken, error) {
+	name, _, _ := azdo.ParsePatMarker(req.DisplayName)
+	patToken := azdo.PatToken{
+		AuthorizationID: fmt.Sprintf("pat-%d", len(f.tokens)+1),
+		DisplayName:     req.DisplayName,
+		Scope:           req.Scope,
+		ValidTo:         req.ValidTo,
+		Token:           "secret-" + name,
+	}
+	f.tokens[patToken.AuthorizationID] = patToken
+	return &patToken, nil
+}
+
+func (f *fakePatClient) Get(_ context.Context, authorizationID string) (*azdo.PatToken, error) {
+	patToken, ok := f.tokens[authorizationID]
+	if !ok {
+		return nil, &azdo.APIError{Method: "GET", StatusCode: 404}
+	}
+	patToken.Token = ""
+	return &patToken, nil
+}
+
+func (f *fakePatClient) List(_ context.Context, _ azdo.ListPatsOptions) ([]azdo.PatToken, error) {
+	patTokens := []azdo.PatToken{}
+	for _, patToken := range f.tokens {
+		patToken.Token = ""
+		patTokens = append(patTokens, patToken)
+	}
+	return patTokens, nil
+}
+
+func (f *fakePatClient) Update(_ context.Context, req azdo.UpdatePatRequest) (*azdo.PatToken, error) {
+	patToken, ok := f.tokens[req.AuthorizationID]
+	if !ok {
+		return nil, &azdo.APIError{Method: "PUT", StatusCode: 404}
+	}
+	patToken.DisplayName, patToken.Scope, patToken.ValidTo = req.DisplayName, req.Scope, req.ValidTo
+	f.tokens[req.AuthorizationID] = patToken
+	return &patToken, nil
+}
+
+func (f *fakePatClient) Revoke(_ context.Context, authorizationID string) error {
+	if _, ok := f.tokens[authorizationID]; !ok {
+		return &azdo.APIError{Method: "DELETE", StatusCode: 404}
+	}
+	delete(f.tokens, authorizationID)
+	f.revoked = append(f.revoked, authorizationID)
+	return nil
+}
+
+func newTestAzurePatResource(client *fakePatClient) *AzurePatResource {
+	r := NewAzurePatResource().(*AzurePatResource)
+	r.newPatClient = func(endpoint string, _ azdo.TokenProvider) azdo.PatClient {
+		client.endpoint = endpoint
+		return client
+	}
+	return r
+}
+
+func testAzurePatModel() AzurePatResourceModel {
+	return AzurePatResourceModel{
+		AzureAuthModel: AzureAuthModel{
+			AppClientID:             types.StringValue("6145d7e0-7adf-4a48-b516-4f61cb047efd"),
+			Authority:               types.StringValue("128c6ba1-f30f-4176-87d4-a93c61ae4ef0"),
+			AzureDevopsUser:         types.StringValue("user@myorganization.com"),
+			AzureDevopsPassword:     types.StringValue("usersuperpassword"),
+			AzureDevopsOrganization: types.StringValue("myorganization"),
+			AzureDevopsPatEndpoint:  types.StringUnknown(),
+			IsAppRegistrationPublic: types.BoolValue(true),
+		},
+		PatName:              types.StringValue("gitops"),
+		AzureDevopsPatScopes: types.StringValue("vso.code"),
+		ValidityDays:         types.Int64Value(PAT_DEFAULT_VALIDITY_DAYS),
+		Pat:                  types.StringUnknown(),
+		PatID:                types.StringUnknown(),
+	}
+}
+
+func testResourceSchema(t *testing.T, r resource.Resource) resource.SchemaResponse {
+	t.Helper()
+
+	schemaResp := resource.SchemaResponse{}
+	r.Schema(context.Background(), resource.SchemaRequest{}, &schemaResp)
+	if schemaResp.Diagnostics.HasError() {
+		t.Fatalf("schema diagnostics: %v", schemaResp.Diagnostics)
+	}
+	return schemaResp
+}
+
+// testResourceData sets the plan, state and configuration of a resource from models in unit tests.
+type testResourceData struct {
+	t          *testing.T
+	schemaResp resource.SchemaResponse
+	null       tftypes.Value
+}
+
+func newTestResourceData(t *testing.T, r resource.Resource) testResourceData {
+	t.Helper()
+
+	schemaResp := testResourceSchema(t, r)
+	return testResourceData{t: t, schemaResp: schemaResp, null: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(context.Background()), nil)}
+}
+
+// state returns the state holding model, a null state when model is nil.
+func (d testResourceData) state(model any) tfsdk.State {
+	d.t.Helper()
+
+	state := tfsdk.State{Schema: d.schemaResp.Schema, Raw: d.null}
+	if model == nil {
+		return state
+	}
+	if diags := state.Set(context.Background(), model); diags.HasError() {
+		d.t.Fatalf("diagnostics setting %T: %v", model, diags)
+	}
+	return state
+}
+
+func (d testResourceData) plan(model any) tfsdk.Plan {
+	d.t.Helper()
+
+	state := d.state(model)
+	return tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+}
+
+func (d testResourceData) config(model any) tfsdk.Config {
+	d.t.Helper()
+
+	state := d.state(model)
+	return tfsdk.Config{Schema: state.Schema, Raw: state.Raw}
+}
+
+// create runs Create of r with model as plan and configuration.
+func (d testResourceData) create(r resource.Resource, model any) *resource.CreateResponse {
+	d.t.Helper()
+
+	resp := &resource.CreateResponse{State: d.state(nil)}
+	r.Create(context.Background(), resource.CreateRequest{Plan: d.plan(model), Config: d.config(model)}, resp)
+	return resp
+}
+
+func TestAzurePatResource_CreateDelete(t *testing.T) {
+	ctx := context.Background()
+	client := newFakePatClient()
+	r := newTestAzurePatResource(client)
+	testData := newTestResourceData(t, r)
+
+	model := testAzurePatModel()
+	createResp := testData.create(r, &model)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diagnostics: %v", createResp.Diagnostics)
+	}
+
+	var created AzurePatResourceModel
+	createResp.State.Get(ctx, &created)
+	if created.PatID.ValueString() != "pat-1" || created.Pat.ValueString() != "secret-gitops" {
+		t.Errorf("unexpected state after create: pat_id=%s pat=%s", created.PatID, created.Pat)
+	}
+	if created.AzureDevopsPatEndpoint.ValueString() != "https://vssps.dev.azure.com/myorganization/_apis/tokens/pats" || client.endpoint != created.AzureDevopsPatEndpoint.ValueString() {
+		t.Errorf("unexpected PAT endpoint %s, client used %s", created.AzureDevopsPatEndpoint, client.endpoint)
+	}
+
+	deleteResp := &resource.DeleteResponse{State: createResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: createResp.State}, deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("delete diagnostics: %v", deleteResp.Diagnostics)
+	}
+	if len(client.revoked) != 1 || client.revoked[0] != "pat-1" {
+		t.Errorf("expected pat-1 to be revoked, got %v", client.revoked)
+	}
+
+	// A PAT already revoked or expired is deleted without error
+	deleteResp = &resource.DeleteResponse{State: createResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: createResp.State}, deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Errorf("unexpected delete diagnostics for a revoked PAT: %v", deleteResp.Diagnostics)
+	}
+}
+
+func TestAzurePatResource_DeleteWithoutRevoke(t *testing.T) {
+	ctx := context.Background()
+	client := newFakePatClient()
+	r := newTestAzurePatResource(client)
+	testData := newTestResourceData(t, r)
+
+	model := testAzurePatModel()
+	model.RevokeOnDestroy = types.BoolValue(false)
+	createResp := testData.create(r, &model)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diagnostics: %v", createResp.Diagnostics)
+	}
+
+	deleteResp := &resource.DeleteResponse{State: createResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: createResp.State}, deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("delete diagnostics: %v", deleteResp.Diagnostics)
+	}
+	if len(client.revoked) != 0 || len(client.tokens) != 1 {
+		t.Errorf("expected the PAT to be kept, got %v revoked", client.revoked)
+	}
+	// No longer managed, the kept PAT loses its marker
+	if displayName := client.tokens["pat-1"].DisplayName; displayName != "gitops" {
+		t.Errorf("expected the kept PAT to be renamed gitops, got %q", displayName)
+	}
+}
+
+func TestAzurePatResource_KeepThenRecreate(t *testing.T) {
+	ctx := context.Background()
+	client := newFakePatClient()
+	r := newTestAzurePatResource(client)
+	testData := newTestResourceData(t, r)
+
+	model := testAzurePatModel()
+	model.RevokeOnDestroy = types.BoolValue(false)
+	model.OnExisting = types.StringValue(ON_EXISTING_REVOKE)
+	createResp := testData.create(r, &model)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diagnostics: %v", createResp.Diagnostics)
+	}
+
+	// Replaced with create_before_destroy, the PAT saved into state is not taken for a lost one
+	model.ValidityDays = types.Int64Value(90)
+	replaceResp := testData.create(r, &model)
+	if replaceResp.Diagnostics.HasError() {
+		t.Fatalf("replace diagnostics: %v", replaceResp.Diagnostics)
+	}
+
+	deleteResp := &resource.DeleteResponse{State: createResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: createResp.State}, deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("delete diagnostics: %v", deleteResp.Diagnostics)
+	}
+
+	// Created again with the same attributes once destroyed
+	model.ValidityDays = types.Int64Value(PAT_DEFAULT_VALIDITY_DAYS)
+	recreateResp := testData.create(r, &model)
+	if recreateResp.Diagnostics.HasError() {
+		t.Fatalf("recreate diagnostics: %v", recreateResp.Diagnostics)
+	}
+	if len(client.revoked) != 0 || len(client.tokens) != 3 {
+		t.Errorf("expected the kept PATs to stay active, got %v revoked", client.revoked)
+	}
+	var recreated AzurePatResourceModel
+	recreateResp.State.Get(ctx, &recreated)
+	if recreated.PatID.ValueString() != "pat-3" || recreated.Pat.ValueString() == "" {
+		t.Errorf("expected a new PAT, got pat_id=%s", recreated.PatID)
+	}
+}
+
+func TestAzurePatResource_CreateMissingCredentials(t *testing.T) {
+	client := newFakePatClient()
+	r := newTestAzurePatResource(client)
+	testData := newTestResourceData(t, r)
+
+	model := testAzurePatModel()
+	model.AzureDevopsPassword = types.StringNull()
+	createResp := testData.create(r, &model)
+	if !createResp.Diagnostics.HasError() {
+		t.Error("expected an error without azure_devops_password")
+	}
+	if len(client.tokens) != 0 {
+		t.Errorf("no PAT should be created, got %v", client.tokens)
+	}
+}
+
+func TestAzurePatResource_CreateOnExisting(t *testing.T) {
+	ctx := context.Background()
+	model := testAzurePatModel()
+	markerKey := model.patMarkerKey()
+	model.AzureDevopsPatScopes = types.StringValue("vso.build")
+	otherKey := model.patMarkerKey()
+	for _, tc := range []struct {
+		onExisting     string
+		expectError    bool
+		expectPatID    string
+		expectRevoked  []string
+		expectPatCount int
+	}{
+		{onExisting: ON_EXISTING_REVOKE, expectPatID: "pat-3", expectRevoked: []string{"lost-2", "lost-1"}, expectPatCount: 3},
+		{onExisting: ON_EXISTING_ADOPT, expectPatID: "lost-2", expectRevoked: []string{"lost-1"}, expectPatCount: 3},
+		{onExisting: ON_EXISTING_ERROR, expectError: true, expectPatCount: 4},
+	} {
+		t.Run(tc.onExisting, func(t *testing.T) {
+			client := newFakePatClient()
+			now := time.Now()
+			client.tokens["lost-1"] = azdo.PatToken{AuthorizationID: "lost-1", DisplayName: azdo.MarkedDisplayName("gitops", markerKey), ValidFrom: now.Add(-2 * time.Minute)}
+			client.tokens["lost-2"] = azdo.PatToken{AuthorizationID: "lost-2", DisplayName: azdo.MarkedDisplayName("gitops", markerKey), ValidFrom: now.Add(-time.Minute)}
+			client.tokens["other"] = azdo.PatToken{AuthorizationID: "other", DisplayName: azdo.MarkedDisplayName("gitops", otherKey)}
+			// Saved into state by another resource, or kept by revoke_on_destroy = false
+			client.tokens["saved"] = azdo.PatToken{AuthorizationID: "saved", DisplayName: azdo.ManagedDisplayName("gitops"), ValidFrom: now}
+			r := newTestAzurePatResource(client)
+			testData := newTestResourceData(t, r)
+
+			model := testAzurePatModel()
+			model.OnExisting = types.StringValue(tc.onExisting)
+			createResp := testData.create(r, &model)
+			if createResp.Diagnostics.HasError() != tc.expectError {
+				t.Fatalf("unexpected create diagnostics: %v", createResp.Diagnostics)
+			}
+			if len(client.tokens) != tc.expectPatCount || fmt.Sprint(client.revoked) != fmt.Sprint(tc.expectRevoked) {
+				t.Errorf("expected %d PATs and %v revoked, got %v and %v revoked", tc.expectPatCount, tc.expectRevoked, client.tokens, client.revoked)
+			}
+			for _, patID := range []string{"other", "saved"} {
+				if _, ok := client.tokens[patID]; !ok {
+					t.Errorf("PAT %s should be kept", patID)
+				}
+			}
+			if tc.expectError {
+				// Importing it would not keep its secret either, the advice only points at on_existing
+				if detail := createResp.Diagnostics[0].Detail(); !strings.Contains(detail, `on_existing = "adopt"`) || strings.Contains(detail, "import") {
+					t.Errorf("unexpected advice %q", detail)
+				}
+				return
+			}
+
+			var created AzurePatResourceModel
+			createResp.State.Get(ctx, &created)
+			if created.PatID.ValueString() != tc.expectPatID {
+				t.Errorf("expected pat_id %s, got %s", tc.expectPatID, created.PatID)
+			}
+			if displayName := client.tokens[tc.expectPatID].DisplayName; displayName != azdo.ManagedDisplayName("gitops") {
+				t.Errorf("expected the PAT saved into state to be renamed, got %q", displayName)
+			}
+		})
+	}
+}
+
+func TestAzurePatResource_ModifyPlanExpiryWarning(t *testing.T) {
+	ctx := context.Background()
+	r := newTestAzurePatResource(newFakePatClient())
+
+	for name, tc := range map[string]struct {
+		validTo       time.Time
+		warningDays   int64
+		replaced      bool
+		change        func(model *AzurePatResourceModel)
+		expectWarning string
+	}{
+		"expiring":      {validTo: time.Now().Add(10*24*time.Hour + time.Hour), warningDays: 30, expectWarning: "PAT Expiring Soon"},
+		"expired":       {validTo: time.Now().Add(-time.Hour), warningDays: 30, expectWarning: "PAT Expired"},
+		"not yet":       {validTo: time.Now().Add(10*24*time.Hour + time.Hour), warningDays: 5},
+		"disabled":      {validTo: time.Now().Add(-time.Hour), warningDays: 0},
+		"being rotated": {validTo: time.Now().Add(-time.Hour), warningDays: 30, replaced: true},
+		"scopes changed": {validTo: time.Now().Add(-time.Hour), warningDays: 30, change: func(model *AzurePatResourceModel) {
+			model.AzureDevopsPatScopes = types.StringValue("vso.code vso.build")
+		}},
+		"renamed": {validTo: time.Now().Add(-time.Hour), warningDays: 30, change: func(model *AzurePatResourceModel) {
+			model.PatName = types.StringUnknown()
+		}},
+		"validity changed": {validTo: time.Now().Add(-time.Hour), warningDays: 30, change: func(model *AzurePatResourceModel) {
+			model.ValidityDays = types.Int64Value(90)
+		}},
+		"updated in place": {validTo: time.Now().Add(-time.Hour), warningDays: 30, expectWarning: "PAT Expired", change: func(model *AzurePatResourceModel) {
+			model.RevokeOnDestroy = types.BoolValue(false)
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testData := newTestResourceData(t, r)
+			model := testAzurePatModel()
+			model.PatID = types.StringValue("pat-1")
+			model.Pat = types.StringValue("secret-gitops")
+			model.ExpiryWarningDays = types.Int64Value(tc.warningDays)
+			model.ValidTo = types.StringValue(tc.validTo.UTC().Format(time.RFC3339))
+			state := testData.state(&model)
+			if tc.replaced {
+				model.PatID = types.StringUnknown()
+			}
+			if tc.change != nil {
+				tc.change(&model)
+			}
+			plan := testData.plan(&model)
+
+			resp := &resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: state, Config: testData.config(&model)}, resp)
+			warnings := resp.Diagnostics.Warnings()
+			if tc.expectWarning == "" {
+				if len(warnings) != 0 {
+					t.Errorf("unexpected warnings %v", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || warnings[0].Summary() != tc.expectWarning || !strings.Contains(warnings[0].Detail(), `"gitops"`) || !strings.Contains(warnings[0].Detail(), model.ValidTo.ValueString()) {
+				t.Errorf("expected a %q warning naming the PAT and its expiry, got %v", tc.expectWarning, warnings)
+			}
+		})
+	}
+
+	if days := daysUntil(time.Now().Add(10*24*time.Hour+time.Hour), time.Now()); days != 10 {
+		t.Errorf("expected 10 days until expiry, got %d", days)
+	}
+}
+
+// fakeTokenAdminClient answers a fixed PAT policy in place of the token admin API.
+type fakeTokenAdminClient struct {
+	policy azdo.PatPolicy
+	err    error
+	reads  int
+}
+
+func (f *fakeTokenAdminClient) GetPatPolicy(_ context.Context) (*azdo.PatPolicy, error) {
+	f.reads++
+	if f.err != nil {
+		return nil, f.err
+	}
+	policy := f.policy
+	return &policy, nil
+}
+
+func (f *fakeTokenAdminClient) ListUserPats(_ context.Context, _ string) ([]azdo.SessionToken, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeTokenAdminClient) RevokeAuthorizations(_ context.Context, _ []string) error {
+	return errors.New("not implemented")
+}
+
+func TestAzurePatResource_ModifyPlanPatPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := azdo.PatPolicy{MaxLifespanDays: 30, RestrictFullScope: true}
+
+	for name, tc := range map[string]struct {
+		onPolicyViolation string
+		scopes            string
+		validityDays      int64
+		readErr           error
+		switchPublic      bool
+		expectReads       int
+		expectWarning     string
+		expectError       string
+	}{
+		"ignored":            {onPolicyViolation: ON_POLICY_VIOLATION_IGNORE, scopes: "app_token", validityDays: 90},
+		"within policy":      {onPolicyViolation: ON_POLICY_VIOLATION_ERROR, scopes: "vso.code", validityDays: 30, expectReads: 1},
+		"clamped":            {onPolicyViolation: ON_POLICY_VIOLATION_CLAMP, scopes: "vso.code", validityDays: 90, expectReads: 1, expectWarning: "PAT Validity Clamped"},
+		"too long":           {onPolicyViolation: ON_POLICY_VIOLATION_ERROR, scopes: "vso.code", validityDays: 90, expectReads: 1, expectError: "PAT Policy Violation"},
+		"full scope":         {onPolicyViolation: ON_POLICY_VIOLATION_CLAMP, scopes: "app_token", validityDays: 30, expectReads: 1, expectError: "PAT Policy Violation"},
+		"policy unreadable":  {onPolicyViolation: ON_POLICY_VIOLATION_ERROR, scopes: "vso.code", validityDays: 90, readErr: &azdo.APIError{Method: "GET", StatusCode: 403}, expectReads: 1, expectError: "Client Error"},
+		"az cli switch":      {onPolicyViolation: ON_POLICY_VIOLATION_ERROR, scopes: "app_token", validityDays: 90, switchPublic: true},
+		"policy unavailable": {onPolicyViolation: ON_POLICY_VIOLATION_CLAMP, scopes: "app_token", validityDays: 90, readErr: &azdo.APIError{Method: "GET", StatusCode: 404}, expectReads: 1, expectWarning: "PAT Policy Not Checked"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := newTestAzurePatResource(newFakePatClient())
+			admin := &fakeTokenAdminClient{policy: policy, err: tc.readErr}
+			r.newTokenAdminClient = func(_ string, _ azdo.TokenProvider) azdo.TokenAdminClient {
+				return admin
+			}
+			testData := newTestResourceData(t, r)
+
+			model := testAzurePatModel()
+			model.AzureDevopsPatScopes = types.StringValue(tc.scopes)
+			model.ValidityDays = types.Int64Value(tc.validityDays)
+			model.OnPolicyViolation = types.StringValue(tc.onPolicyViolation)
+			if tc.switchPublic {
+				model.SwitchPrivatePublic = types.BoolValue(true)
+			}
+			plan := testData.plan(&model)
+			model.AzureDevopsPatEndpoint = types.StringNull()
+			model.Pat = types.StringNull()
+			model.PatID = types.StringNull()
+
+			resp := &resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: testData.state(nil), Config: testData.config(&model)}, resp)
+
+			if admin.reads != tc.expectReads {
+				t.Errorf("expected %d policy reads, got %d", tc.expectReads, admin.reads)
+			}
+			warnings, errs := resp.Diagnostics.Warnings(), resp.Diagnostics.Errors()
+			if (tc.expectWarning == "" && len(warnings) != 0) || (tc.expectWarning != "" && (len(warnings) != 1 || warnings[0].Summary() != tc.expectWarning)) {
+				t.Errorf("expected warning %q, got %v", tc.expectWarning, warnings)
+			}
+			if (tc.expectError == "" && len(errs) != 0) || (tc.expectError != "" && (len(errs) != 1 || errs[0].Summary() != tc.expectError)) {
+				t.Errorf("expected error %q, got %v", tc.expectError, errs)
+			}
+		})
+	}
+}
+
+func TestAzurePatResource_ImportedWithAzCliSwitch(t *testing.T) {
+	ctx := context.Background()
+	client := newFakePatClient()
+	client.tokens["imported"] = azdo.PatToken{AuthorizationID: "imported", DisplayName: "laptop", Scope: "vso.code", ValidTo: time.Now().Add(24 * time.Hour)}
+	r := newTestAzurePatResource(client)
+	clients := 0
+	r.newPatClient = func(_ string, _ azdo.TokenProvider) azdo.PatClient {
+		clients++
+		return client
+	}
+	testData := newTestResourceData(t, r)
+
+	state := testData.state(&AzurePatResourceModel{
+		AzureAuthModel: AzureAuthModel{AzureDevopsOrganization: types.StringValue("myorganization")},
+		PatID:          types.StringValue("imported"),
+	})
+	for name, tc := range map[string]struct {
+		patName     string
+		expectError string
+	}{
+		"matching":   {patName: "laptop"},
+		"mismatched": {patName: "gitops", expectError: "Imported PAT Mismatch"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			clients = 0
+			model := testAzurePatModel()
+			model.PatName = types.StringValue(tc.patName)
+			model.PatID = types.StringValue("imported")
+			model.SwitchPrivatePublic = types.BoolValue(true)
+			plan := testData.plan(&model)
+			config := testData.config(&model)
+
+			// The workaround would switch the app public on every plan, the imported PAT is checked on apply
+			planResp := &resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: state, Config: config}, planResp)
+			if planResp.Diagnostics.HasError() || clients != 0 {
+				t.Fatalf("expected the plan to skip the imported PAT, got %d clients and %v", clients, planResp.Diagnostics)
+			}
+
+			updateResp := &resource.UpdateResponse{State: state}
+			r.Update(ctx, resource.UpdateRequest{Plan: plan, State: state, Config: config}, updateResp)
+			errs := updateResp.Diagnostics.Errors()
+			if (tc.expectError == "" && len(errs) != 0) || (tc.expectError != "" && (len(errs) != 1 || errs[0].Summary() != tc.expectError)) {
+				t.Errorf("expected error %q, got %v", tc.expectError, errs)
+			}
+		})
+	}
+}
+
+// countingTransport fails every request, counting them
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return nil, fmt.Errorf("unexpected request %s %s", req.Method, req.URL)
+}
+
+func TestAzurePatResource_VerifyCredentialsWithAzCliSwitch(t *testing.T) {
+	ctx := context.Background()
+	r := newTestAzurePatResource(newFakePatClient())
+	r.verifyCredentialsOnPlan = true
+	r.tokens = &azdo.TokenCache{}
+	transport := &countingTransport{}
+	r.client = &http.Client{Transport: transport}
+	testData := newTestResourceData(t, r)
+
+	for name, tc := range map[string]struct {
+		switchPrivatePublic bool
+		expectRequests      bool
+	}{
+		"verified":  {expectRequests: true},
+		"az switch": {switchPrivatePublic: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			transport.requests = 0
+			model := testAzurePatModel()
+			model.AzureDevopsPatEndpoint = types.StringNull()
+			model.SwitchPrivatePublic = types.BoolValue(tc.switchPrivatePublic)
+			plan := testData.plan(&model)
+
+			resp := &resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: testData.state(nil), Config: testData.config(&model)}, resp)
+			if tc.expectRequests && (transport.requests == 0 || !resp.Diagnostics.HasError()) {
+				t.Errorf("expected the credentials to be verified, got %d requests and %v", transport.requests, resp.Diagnostics)
+			}
+			// The workaround would switch the app public on every plan
+			if !tc.expectRequests && (transport.requests != 0 || resp.Diagnostics.HasError()) {
+				t.Errorf("expected the verification to be skipped, got %d requests and %v", transport.requests, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestAzurePatResource_CreatePgpKey(t *testing.T) {
+	ctx := context.Background()
+	client := newFakePatClient()
+	r := newTestAzurePatResource(client)
+	testData := newTestResourceData(t, r)
+
+	entity, err := openpgp.NewEntity("gitops", "", "gitops@myorganization.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	armorWriter, _ := armor.Encode(buf, openpgp.PublicKeyType, nil)
+	if err := entity.Serialize(armorWriter); err != nil {
+		t.Fatal(err)
+	}
+	armorWriter.Close()
+	r.pgpKeybaseDir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(r.pgpKeybaseDir, "gitops.asc"), buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	model := testAzurePatModel()
+	model.PgpKey = types.StringValue("keybase:gitops")
+	createResp := testData.create(r, &model)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diagnostics: %v", createResp.Diagnostics)
+	}
+
+	var created AzurePatResourceModel
+	createResp.State.Get(ctx, &created)
+	if created.Pat.ValueString() != "" {
+		t.Errorf("pat should be empty with pgp_key, got %s", created.Pat)
+	}
+	if created.KeyFingerprint.ValueString() != hex.EncodeToString(entity.PrimaryKey.Fingerprint) {
+		t.Errorf("unexpected key_fingerprint %s", created.KeyFingerprint)
+	}
+	block, err := armor.Decode(strings.NewReader(created.EncryptedPat.ValueString()))
+	if err != nil {
+		t.Fatalf("encrypted_pat is not armored: %v", err)
+	}
+	md, err := openpgp.ReadMessage(block.Body, openpgp.EntityList{entity}, nil, nil)
+	if err != nil {
+		t.Fatalf("could not decrypt encrypted_pat: %v", err)
+	}
+	plaintext := &bytes.Buffer{}
+	plaintext.ReadFrom(md.UnverifiedBody)
+	if plaintext.String() != "secret-gitops" {
+		t.Errorf("expected encrypted_pat to hold secret-gitops, got %s", plaintext)
+	}
+
+	// An unreadable key fails before the PAT is created
+	model.PgpKey = types.StringValue("keybase:unknown")
+	createResp = testData.create(r, &model)
+	if !createResp.Diagnostics.HasError() {
+		t.Error("expected an error for an unknown keybase user")
+	}
+	if len(client.tokens) != 1 {
+		t.Errorf("no other PAT should be created, got %v", client.tokens)
+	}
+}
+
+const (
+	testAccClientID = "6145d7e0-7adf-4a48-b516-4f61cb047efd"
+	testAccUser     = "user@myorganization.com"
+	testAccUserID   = "0d0c3e7a-2a61-4f9b-a3a8-4f6b0e1c9d11"
+)
+
+func newTestAccServer(t *testing.T, public bool) *azdotest.Server {
+	t.Helper()
+
+	server := azdotest.NewServer()
+	t.Cleanup(server.Close)
+	server.AddApp(azdotest.App{
+		ClientID:               testAccClientID,
+		ObjectID:               "8a5e3a53-4b7c-4b1c-9df0-3c0b7b7a3e21",
+		IsFallbackPublicClient: public,
+		ClientSecret:           "appsecret",
+	})
+	server.AddUser(azdotest.User{
+		Username:    testAccUser,
+		Password:    "usersuperpassword",
+		DisplayName: "Azure Devops User",
+		ID:          testAccUserID,
+		Descriptor:  "aad.MGQwYzNlN2EtMmE2MS00ZjliLWEzYTgtNGY2YjBlMWM5ZDEx",
+	})
+	return server
+}
+
+func testAccAzurePatPasswordConfig(server *azdotest.Server, rotation string) string {
+	return testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = "vso.code vso.packaging"
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = "usersuperpassword"
+  rotate_when_changed       = %[4]q
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser, rotation)
+}
+
+// testAccCheckActivePat checks the PAT in state is the only active one on the fake server.
+func testAccCheckActivePat(server *azdotest.Server, patID *string) resourcetest.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["helloasso_azure_pat.test"]
+		if !ok {
+			return fmt.Errorf("helloasso_azure_pat.test not found in state")
+		}
+		pats := server.ActivePats()
+		if len(pats) != 1 {
+			return fmt.Errorf("expected 1 active PAT, got %d", len(pats))
+		}
+		if pats[0].AuthorizationID != rs.Primary.Attributes["pat_id"] || pats[0].Token != rs.Primary.Attributes["pat"] {
+			return fmt.Errorf("state PAT %s does not match active PAT %s", rs.Primary.Attributes["pat_id"], pats[0].AuthorizationID)
+		}
+		if name, _, ok := azdo.ParsePatMarker(pats[0].DisplayName); !ok || name != "gitops" || pats[0].Scope != "vso.code vso.packaging" {
+			return fmt.Errorf("unexpected PAT %+v", pats[0].PatToken)
+		}
+		if patID != nil {
+			*patID = pats[0].AuthorizationID
+		}
+		return nil
+	}
+}
+
+func testAccCheckAzurePatDestroy(server *azdotest.Server) resourcetest.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if pats := server.ActivePats(); len(pats) != 0 {
+			return fmt.Errorf("expected every PAT to be revoked, %d still active", len(pats))
+		}
+		return nil
+	}
+}
+
+func TestAccAzurePatResource(t *testing.T) {
+	server := newTestAccServer(t, true)
+	var firstPatID, rotatedPatID string
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		CheckDestroy:             testAccCheckAzurePatDestroy(server),
+		Steps: []resourcetest.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccAzurePatPasswordConfig(server, "1"),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					testAccCheckActivePat(server, &firstPatID),
+					resourcetest.TestCheckResourceAttr("helloasso_azure_pat.test", "azure_devops_pat_endpoint", server.Cloud().PatEndpoint(azdotest.ORGANIZATION)),
+					resourcetest.TestCheckResourceAttr("helloasso_azure_pat.test", "authority", server.URL+"/"+azdotest.TENANT_ID),
+					resourcetest.TestCheckResourceAttrSet("helloasso_azure_pat.test", "valid_to"),
+					resourcetest.TestMatchResourceAttr("helloasso_azure_pat.test", "days_until_expiry", regexp.MustCompile(`^36[45]$`)),
+				),
+			},
+			// Rotation testing
+			{
+				Config: testAccAzurePatPasswordConfig(server, "2"),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					testAccCheckActivePat(server, &rotatedPatID),
+					func(s *terraform.State) error {
+						if rotatedPatID == firstPatID {
+							return fmt.Errorf("expected PAT %s to be rotated", firstPatID)
+						}
+						return nil
+					},
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName: "helloasso_azure_pat.test",
+				ImportState:  true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return azdotest.ORGANIZATION + "/" + rotatedPatID, nil
+				},
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "pat_id",
+				// Neither the PAT secret nor the credentials can be read back, the next apply records them, see TestAccAzurePatResource_ImportKeepsPat
+				ImportStateVerifyIgnore: []string{
+					"pat", "pat_name", "azure_devops_pat_scopes", "rotate_when_changed", "app_client_id", "authority",
+					"azure_devops_user", "azure_devops_password", "azure_devops_pat_endpoint", "is_app_registration_public", "on_existing", "revoke_on_destroy", "expiry_warning_days", "valid_to", "days_until_expiry",
+					"validity_days", "on_policy_violation",
+				},
+			},
+			// Refresh testing: a PAT revoked outside of terraform is created again
+			{
+				PreConfig: func() {
+					server.RevokePat(rotatedPatID)
+				},
+				Config: testAccAzurePatPasswordConfig(server, "2"),
+				Check:  testAccCheckActivePat(server, nil),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+// The first apply after an import records the imported PAT instead of replacing it
+func TestAccAzurePatResource_ImportKeepsPat(t *testing.T) {
+	server := newTestAccServer(t, true)
+	imported := server.AddPat(testAccUserID, "gitops", "vso.packaging vso.code")
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		CheckDestroy:             testAccCheckAzurePatDestroy(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config:             testAccAzurePatPasswordConfig(server, "1"),
+				ResourceName:       "helloasso_azure_pat.test",
+				ImportState:        true,
+				ImportStateId:      azdotest.ORGANIZATION + "/" + imported.AuthorizationID,
+				ImportStatePersist: true,
+			},
+			{
+				Config: testAccAzurePatPasswordConfig(server, "1"),
+				ConfigPlanChecks: resourcetest.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("helloasso_azure_pat.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					resourcetest.TestCheckResourceAttr("helloasso_azure_pat.test", "pat_id", imported.AuthorizationID),
+					resourcetest.TestCheckResourceAttr("helloasso_azure_pat.test", "valid_to", imported.ValidTo.Format(time.RFC3339)),
+					testAccCheckPatsActive(server, map[string]bool{"gitops": true}),
+				),
+			},
+			{
+				Config:   testAccAzurePatPasswordConfig(server, "1"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// An imported PAT not matching the configuration fails the plan, as replacing it could not revoke it
+func TestAccAzurePatResource_ImportMismatch(t *testing.T) {
+	server := newTestAccServer(t, true)
+	imported := server.AddPat(testAccUserID, "laptop", "vso.code")
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config:             testAccAzurePatPasswordConfig(server, "1"),
+				ResourceName:       "helloasso_azure_pat.test",
+				ImportState:        true,
+				ImportStateId:      azdotest.ORGANIZATION + "/" + imported.AuthorizationID,
+				ImportStatePersist: true,
+			},
+			{
+				Config:      testAccAzurePatPasswordConfig(server, "1"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Imported\s+PAT\s+` + imported.AuthorizationID + `\s+is\s+named\s+"laptop"`),
+			},
+			{
+				PreConfig: func() {
+					server.RevokePat(imported.AuthorizationID)
+				},
+				Config:      testAccAzurePatPasswordConfig(server, "1"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Imported\s+PAT\s+Not\s+Found`),
+			},
+		},
+	})
+}
+
+func TestAccAzurePatResource_ClientSecret(t *testing.T) {
+	server := newTestAccServer(t, false)
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		CheckDestroy:             testAccCheckAzurePatDestroy(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config: testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                   = "gitops"
+  azure_devops_pat_scopes    = "vso.code vso.packaging"
+  azure_devops_organization  = %[1]q
+  app_client_id              = %[2]q
+  is_app_registration_public = false
+  app_client_secret          = "appsecret"
+}
+`, azdotest.ORGANIZATION, testAccClientID),
+				Check: testAccCheckActivePat(server, nil),
+			},
+		},
+	})
+}
+
+func TestAccAzurePatResource_PasswordFile(t *testing.T) {
+	server := newTestAccServer(t, true)
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("usersuperpassword\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := func(attribute string, value string) string {
+		return testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = "vso.code vso.packaging"
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  %[4]s = %[5]q
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser, attribute, value)
+	}
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		CheckDestroy:             testAccCheckAzurePatDestroy(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config:      config("azure_devops_password_file", passwordFile+".missing"),
+				ExpectError: regexp.MustCompile(`Could not read azure_devops_password_file`),
+			},
+			{
+				Config: config("azure_devops_password_file", passwordFile),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					testAccCheckActivePat(server, nil),
+					resourcetest.TestCheckNoResourceAttr("helloasso_azure_pat.test", "azure_devops_password"),
+				),
+			},
+			{
+				Config: config("azure_devops_password_command", "cat "+passwordFile),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					testAccCheckActivePat(server, nil),
+					resourcetest.TestCheckNoResourceAttr("helloasso_azure_pat.test", "azure_devops_password"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAzurePatResource_UserChanged(t *testing.T) {
+	server := newTestAccServer(t, true)
+	server.AddUser(azdotest.User{
+		Username:    "admin@myorganization.com",
+		Password:    "adminsuperpassword",
+		DisplayName: "Azure Devops Admin",
+		ID:          "5b1f0c2e-8d3a-4e6b-9f7c-1a2b3c4d5e6f",
+		Descriptor:  "aad.NWIxZjBjMmUtOGQzYS00ZTZiLTlmN2MtMWEyYjNjNGQ1ZTZm",
+	})
+	config := func(user string, password string) string {
+		return testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = "vso.code vso.packaging"
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = %[4]q
+}
+`, azdotest.ORGANIZATION, testAccClientID, user, password)
+	}
+	var userPatID, adminPatID string
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		CheckDestroy:             testAccCheckAzurePatDestroy(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config: config(testAccUser, "usersuperpassword"),
+				Check:  testAccCheckActivePat(server, &userPatID),
+			},
+			// The PAT of the previous user is revoked, and one is created for the new user
+			{
+				Config: config("admin@myorganization.com", "adminsuperpassword"),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					testAccCheckActivePat(server, &adminPatID),
+					func(s *terraform.State) error {
+						if adminPatID == userPatID {
+							return fmt.Errorf("expected PAT %s to be replaced", userPatID)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccAzurePatResource_VerifyCredentialsOnPlan(t *testing.T) {
+	server := newTestAccServer(t, true)
+	config := func(password string) string {
+		return testAccFakeProviderConfig(server, "verify_credentials_on_plan = true") + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = "vso.code vso.packaging"
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = %[4]q
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser, password)
+	}
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		CheckDestroy:             testAccCheckAzurePatDestroy(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config:             config("wrongpassword"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+				ExpectError:        regexp.MustCompile(`AADSTS50126`),
+			},
+			{
+				Config: config("usersuperpassword"),
+				Check:  testAccCheckActivePat(server, nil),
+			},
+		},
+	})
+}
+
+func TestAccAzurePatResource_WaitUntilUsable(t *testing.T) {
+	server := newTestAccServer(t, true)
+	config := func(timeout int) string {
+		return testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = "vso.code vso.packaging"
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = "usersuperpassword"
+  wait_until_usable_timeout = %[4]d
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser, timeout)
+	}
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		CheckDestroy:             testAccCheckAzurePatDestroy(server),
+		Steps: []resourcetest.TestStep{
+			// A PAT still not usable after the timeout is revoked
+			{
+				PreConfig: func() {
+					server.SetPatPropagationDelay(time.Hour)
+				},
+				Config:      config(2),
+				ExpectError: regexp.MustCompile(`is\s+not\s+usable`),
+			},
+			{
+				PreConfig: func() {
+					if pats := server.Pats(); len(pats) != 1 || !pats[0].Revoked {
+						t.Errorf("expected the unusable PAT to be revoked, got %+v", pats)
+					}
+					server.SetPatPropagationDelay(1500 * time.Millisecond)
+				},
+				Config: config(10),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					testAccCheckActivePat(server, nil),
+					func(s *terraform.State) error {
+						pat := server.ActivePats()[0]
+						if time.Now().Before(pat.UsableFrom) {
+							return fmt.Errorf("PAT %s was reported before being usable", pat.AuthorizationID)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// The PAT is checked against the organization APIs, not the vssps host of the PAT endpoint
+func TestAccAzurePatResource_WaitUntilUsablePatEndpoint(t *testing.T) {
+	server := newTestAccServer(t, true)
+	server.SetPatPropagationDelay(500 * time.Millisecond)
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		CheckDestroy:             testAccCheckAzurePatDestroy(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config: testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = "vso.code vso.packaging"
+  azure_devops_pat_endpoint = "%[1]s/%[2]s/%[3]s/_apis/tokens/pats"
+  authority                 = %[4]q
+  app_client_id             = %[5]q
+  azure_devops_user         = %[6]q
+  azure_devops_password     = "usersuperpassword"
+  wait_until_usable_timeout = 5
+}
+`, server.URL, azdotest.VSSPS_PATH_PREFIX, azdotest.ORGANIZATION, azdotest.TENANT_ID, testAccClientID, testAccUser),
+				Check: testAccCheckActivePat(server, nil),
+			},
+		},
+	})
+}
+
+func TestAccAzurePatResource_KeepOnDestroy(t *testing.T) {
+	server := newTestAccServer(t, true)
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		CheckDestroy: func(s *terraform.State) error {
+			if pats := server.ActivePats(); len(pats) != 1 {
+				return fmt.Errorf("expected the PAT to outlive the resource, %d active", len(pats))
+			}
+			return nil
+		},
+		Steps: []resourcetest.TestStep{
+			{
+				Config: testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = "vso.code vso.packaging"
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = "usersuperpassword"
+  revoke_on_destroy         = false
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser),
+				Check: testAccCheckActivePat(server, nil),
+			},
+		},
+	})
+}
+
+func testAccAzurePatPolicyConfig(server *azdotest.Server, scopes string, validityDays int, onPolicyViolation string) string {
+	return testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = %[4]q
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = "usersuperpassword"
+  validity_days             = %[5]d
+  on_policy_violation       = %[6]q
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser, scopes, validityDays, onPolicyViolation)
+}
+
+func TestAccAzurePatResource_PatPolicy(t *testing.T) {
+	server := newTestAccServer(t, true)
+	server.SetPatPolicy(azdo.PatPolicy{MaxLifespanDays: 30, RestrictFullScope: true})
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		CheckDestroy:             testAccCheckAzurePatDestroy(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config:      testAccAzurePatPolicyConfig(server, "vso.code vso.packaging", 90, "error"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`validity_days\s+90\s+exceeds\s+the\s+30\s+days\s+maximum\s+lifespan`),
+			},
+			{
+				Config:      testAccAzurePatPolicyConfig(server, "app_token", 30, "clamp"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`forbids\s+full-scoped\s+PATs`),
+			},
+			// Without reading the policy, the creation fails during apply
+			{
+				Config:      testAccAzurePatPolicyConfig(server, "vso.code vso.packaging", 90, "ignore"),
+				ExpectError: regexp.MustCompile(`organization\s+PAT\s+policy\s+forbids\s+it`),
+			},
+			{
+				Config: testAccAzurePatPolicyConfig(server, "vso.code vso.packaging", 90, "clamp"),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					testAccCheckActivePat(server, nil),
+					resourcetest.TestCheckResourceAttr("helloasso_azure_pat.test", "validity_days", "90"),
+					resourcetest.TestMatchResourceAttr("helloasso_azure_pat.test", "days_until_expiry", regexp.MustCompile(`^(29|30)$`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAzurePatResource_PatPolicyUnavailable(t *testing.T) {
+	server := newTestAccServer(t, true)
+	server.SetPatPolicyUnavailable(true)
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		CheckDestroy:             testAccCheckAzurePatDestroy(server),
+		Steps: []resourcetest.TestStep{
+			// The checks are skipped with a warning, and the validity is not clamped
+			{
+				Config: testAccAzurePatPolicyConfig(server, "vso.code vso.packaging", 90, "clamp"),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					testAccCheckActivePat(server, nil),
+					resourcetest.TestMatchResourceAttr("helloasso_azure_pat.test", "days_until_expiry", regexp.MustCompile(`^(89|90)$`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAzurePatResource_PasswordOnConfidentialApp(t *testing.T) {
+	server := newTestAccServer(t, false)
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config:      testAccAzurePatPasswordConfig(server, "1"),
+				ExpectError: regexp.MustCompile(`AADSTS7000218`),
+			},
+		},
+	})
+}
+
+func testAccAzurePatWriteOnlyConfig(server *azdotest.Server, password string, version int) string {
+	return testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                         = "gitops"
+  azure_devops_pat_scopes          = "vso.code vso.packaging"
+  azure_devops_organization        = %[1]q
+  app_client_id                    = %[2]q
+  azure_devops_user                = %[3]q
+  azure_devops_password_wo         = %[4]q
+  azure_devops_password_wo_version = %[5]d
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser, password, version)
+}
+
+func TestAccAzurePatResource_WriteOnlyPassword(t *testing.T) {
+	server := newTestAccServer(t, true)
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_11_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		// Without credentials in state, destroy leaves the PAT to expire
+		CheckDestroy: func(s *terraform.State) error {
+			if pats := server.ActivePats(); len(pats) != 1 {
+				return fmt.Errorf("expected the PAT to stay active, got %d active PATs", len(pats))
+			}
+			return nil
+		},
+		Steps: []resourcetest.TestStep{
+			{
+				Config: testAccAzurePatWriteOnlyConfig(server, "usersuperpassword", 1),
+				Check: resourcetest.ComposeAggregateTestCheckFunc(
+					testAccCheckActivePat(server, nil),
+					resourcetest.TestCheckNoResourceAttr("helloasso_azure_pat.test", "azure_devops_password_wo"),
+					resourcetest.TestCheckNoResourceAttr("helloasso_azure_pat.test", "azure_devops_password"),
+				),
+			},
+			// A new version of the password is checked during apply
+			{
+				Config:      testAccAzurePatWriteOnlyConfig(server, "wrongpassword", 2),
+				ExpectError: regexp.MustCompile(`Could not authenticate with the new write-only credentials`),
+			},
+			{
+				Config: testAccAzurePatWriteOnlyConfig(server, "usersuperpassword", 2),
+				Check:  testAccCheckActivePat(server, nil),
+			},
+		},
+	})
+}
+
+func TestAccAzurePatResource_ConflictingPassword(t *testing.T) {
+	server := newTestAccServer(t, true)
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_11_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		Steps: []resourcetest.TestStep{
+			{
+				Config: testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                  = "gitops"
+  azure_devops_pat_scopes   = "vso.code"
+  azure_devops_organization = %[1]q
+  app_client_id             = %[2]q
+  azure_devops_user         = %[3]q
+  azure_devops_password     = "usersuperpassword"
+  azure_devops_password_wo  = "usersuperpassword"
+}
+`, azdotest.ORGANIZATION, testAccClientID, testAccUser),
+				ExpectError: regexp.MustCompile(`Conflicting Attributes`),
+			},
+		},
+	})
+}
+
+func TestAccAzurePatResource_InvalidConfig(t *testing.T) {
+	server := newTestAccServer(t, true)
+	config := func(attributes string) string {
+		return testAccFakeProviderConfig(server) + fmt.Sprintf(`
+resource "helloasso_azure_pat" "test" {
+  pat_name                = "gitops"
+  azure_devops_pat_scopes = "vso.code"
+  %s
+}
+`, attributes)
+	}
+
+	steps := []resourcetest.TestStep{}
+	for attributes, expectError := range map[string]string{
+		// required and conflicting auth attributes
+		`azure_devops_organization = "myorganization"
+  app_client_id = "` + testAccClientID + `"
+  azure_devops_password = "usersuperpassword"`: `azure_devops_user is required with auth_method = "password"`,
+		`azure_devops_organization = "myorganization"
+  app_client_id = "` + testAccClientID + `"
+  azure_devops_user = "` + testAccUser + `"`: `One of azure_devops_password, azure_devops_password_file,`,
+		`azure_devops_organization = "myorganization"
+  app_client_id = "` + testAccClientID + `"
+  is_app_registration_public = false`: `One of app_client_secret, app_client_secret_file,`,
+		`azure_devops_organization = "myorganization"
+  auth_method = "oidc"`: `app_client_id is required with auth_method = "oidc"`,
+		`auth_method = "managed_identity"`: `One of azure_devops_organization or azure_devops_pat_endpoint is required`,
+		`azure_devops_organization = "myorganization"
+  app_client_id = "` + testAccClientID + `"
+  is_app_registration_public = false
+  app_client_secret = "appsecret"
+  app_client_secret_command = "pass show app"`: `app_client_secret and app_client_secret_command can not be set together`,
+		`azure_devops_organization = "myorganization"
+  app_client_id = "` + testAccClientID + `"
+  auth_method = "client_secret"
+  app_client_secret = "appsecret"
+  az_cli_switch_private_app_public = true`: `az_cli_switch_private_app_public only works with the public client flow`,
+		// attribute formats
+		`azure_devops_organization = "myorganization"
+  auth_method = "managed_identity"
+  app_client_id = "myapp"`: `must be a GUID`,
+		`azure_devops_organization = "myorganization"
+  auth_method = "managed_identity"
+  authority = "http://login.microsoftonline.com/` + azdotest.TENANT_ID + `"`: `must be a tenant ID or domain, or an https URL`,
+		`azure_devops_pat_endpoint = "http://vssps.dev.azure.com/myorganization/_apis/tokens/pats"
+  auth_method = "managed_identity"`: `must be an https URL`,
+		`azure_devops_organization = "myorganization"
+  auth_method = "managed_identity"
+  az_cli_switch_private_app_public_wait_delay = -1`: `must be at least 0`,
+		`azure_devops_organization = "myorganization"
+  auth_method = "kerberos"`: `value must be one of`,
+	} {
+		steps = append(steps, resourcetest.TestStep{
+			Config: config(attributes),
+			// Terraform wraps long diagnostics
+			ExpectError: regexp.MustCompile(strings.Join(strings.Fields(regexp.QuoteMeta(expectError)), `\s+`)),
+		})
+	}
+
+	resourcetest.Test(t, resourcetest.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(server),
+		Steps:                    steps,
+	})
+}
@@ -0,0 +1,66 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// tenantRegexp matches a tenant ID, a verified domain, or one of the multi-tenant
+// 'common', 'organizations' and 'consumers' audiences.
+var tenantRegexp = regexp.MustCompile(`^[0-9A-Za-z]([0-9A-Za-z.-]*[0-9A-Za-z])?$`)
+
+// EntraAuthority checks the value is an Entra ID (AzureAD) authority, either an https URL
+// such as https://login.microsoftonline.com/<tenant>, or a bare tenant ID or domain.
+func EntraAuthority() validator.String {
+	return entraAuthorityValidator{}
+}
+
+type entraAuthorityValidator struct{}
+
+func (v entraAuthorityValidator) Description(ctx context.Context) string {
+	return "value must be a tenant ID or domain, or an https URL such as https://login.microsoftonline.com/<tenant>"
+}
+
+func (v entraAuthorityValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v entraAuthorityValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if err := checkEntraAuthority(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Authority", fmt.Sprintf("%s %s, %v", req.Path, v.Description(ctx), err))
+	}
+}
+
+func checkEntraAuthority(authority string) error {
+	if !strings.Contains(authority, "://") {
+		if !tenantRegexp.MatchString(authority) {
+			return fmt.Errorf("%q is not a tenant ID or domain", authority)
+		}
+		return nil
+	}
+
+	u, err := url.Parse(authority)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("scheme must be https, got %q", u.Scheme)
+	}
+	if u.Host == "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("%q must only have a host and a tenant path", authority)
+	}
+	tenant := strings.TrimSuffix(strings.TrimPrefix(u.Path, "/"), "/")
+	if !tenantRegexp.MatchString(tenant) {
+		return fmt.Errorf("path of %q must be a single tenant ID or domain", authority)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package validators
+
+import "testing"
+
+func TestEntraAuthority(t *testing.T) {
+	testStringValidator(t, EntraAuthority(),
+		[]string{
+			"128c6ba1-f30f-4176-87d4-a93c61ae4ef0",
+			"myorganization.onmicrosoft.com",
+			"organizations",
+			"https://login.microsoftonline.com/128c6ba1-f30f-4176-87d4-a93c61ae4ef0",
+			"https://login.microsoftonline.us/myorganization.onmicrosoft.com/",
+			"https://127.0.0.1:8443/common",
+		},
+		[]string{
+			"",
+			"http://login.microsoftonline.com/128c6ba1-f30f-4176-87d4-a93c61ae4ef0",
+			"https://login.microsoftonline.com",
+			"https://login.microsoftonline.com/128c6ba1-f30f-4176-87d4-a93c61ae4ef0/oauth2/v2.0/token",
+			"https://login.microsoftonline.com/common?prompt=login",
+			"my tenant",
+		},
+	)
+}
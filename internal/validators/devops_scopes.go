@@ -0,0 +1,63 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+const DEVOPS_FULL_ACCESS_SCOPE string = "app_token"
+
+// devopsScopeRegexp allows dotted scopes such as 'vso.extension.data_write'
+var devopsScopeRegexp = regexp.MustCompile(`^vso\.[a-z]+([._][a-z]+)*$`)
+
+// DevopsScopes checks the value is a whitespace separated list of Azure Devops scopes,
+// such as 'vso.code vso.packaging_write', or the full access 'app_token'.
+func DevopsScopes() validator.String {
+	return devopsScopesValidator{}
+}
+
+type devopsScopesValidator struct{}
+
+func (v devopsScopesValidator) Description(ctx context.Context) string {
+	return "value must be Azure Devops scopes separated by a whitespace, such as 'vso.code vso.packaging_write', or 'app_token'"
+}
+
+func (v devopsScopesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v devopsScopesValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if err := checkDevopsScopes(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Scopes", fmt.Sprintf("%s %s, %v", req.Path, v.Description(ctx), err))
+	}
+}
+
+func checkDevopsScopes(value string) error {
+	scopes := strings.Fields(value)
+	if len(scopes) == 0 {
+		return fmt.Errorf("got no scope")
+	}
+
+	seen := map[string]bool{}
+	for _, scope := range scopes {
+		if scope != DEVOPS_FULL_ACCESS_SCOPE && !devopsScopeRegexp.MatchString(scope) {
+			return fmt.Errorf("%q is not a scope", scope)
+		}
+		if seen[scope] {
+			return fmt.Errorf("%q is listed twice", scope)
+		}
+		seen[scope] = true
+	}
+	if seen[DEVOPS_FULL_ACCESS_SCOPE] && len(scopes) > 1 {
+		return fmt.Errorf("%q already grants every scope", DEVOPS_FULL_ACCESS_SCOPE)
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package validators
+
+import "testing"
+
+func TestDevopsScopes(t *testing.T) {
+	testStringValidator(t, DevopsScopes(),
+		[]string{"vso.code", "vso.code vso.packaging_write", " vso.build_execute\tvso.release ", "app_token", "vso.extension.data_write", "vso.extension.default vso.code"},
+		[]string{"", "  ", "vso.code,vso.packaging", "code", "vso.code vso.code", "app_token vso.code", "vso.Code", "vso.extension..data", "vso.code.", "vso._code"},
+	)
+}
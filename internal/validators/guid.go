@@ -0,0 +1,41 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+var guidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// AzureGUID checks the value is a GUID, as Azure client, object and tenant IDs.
+func AzureGUID() validator.String {
+	return azureGUIDValidator{}
+}
+
+type azureGUIDValidator struct{}
+
+func (v azureGUIDValidator) Description(ctx context.Context) string {
+	return "value must be a GUID such as 6145d7e0-7adf-4a48-b516-4f61cb047efd"
+}
+
+func (v azureGUIDValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v azureGUIDValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if !IsAzureGUID(req.ConfigValue.ValueString()) {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid GUID", fmt.Sprintf("%s %s, got: %q", req.Path, v.Description(ctx), req.ConfigValue.ValueString()))
+	}
+}
+
+// IsAzureGUID tells whether value is a GUID.
+func IsAzureGUID(value string) bool {
+	return guidRegexp.MatchString(value)
+}
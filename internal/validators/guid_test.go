@@ -0,0 +1,10 @@
+package validators
+
+import "testing"
+
+func TestAzureGUID(t *testing.T) {
+	testStringValidator(t, AzureGUID(),
+		[]string{"6145d7e0-7adf-4a48-b516-4f61cb047efd", "128C6BA1-F30F-4176-87D4-A93C61AE4EF0"},
+		[]string{"", "myapp", "6145d7e0-7adf-4a48-b516-4f61cb047ef", "{6145d7e0-7adf-4a48-b516-4f61cb047efd}", "6145d7e07adf4a48b5164f61cb047efd"},
+	)
+}
@@ -0,0 +1,10 @@
+package validators
+
+import "testing"
+
+func TestRFC3339(t *testing.T) {
+	testStringValidator(t, RFC3339(),
+		[]string{"2026-01-02T15:04:05Z", "2020-01-02T17:04:05+02:00"},
+		[]string{"", "2030-01-02", "2030-01-02 15:04:05", "yesterday"},
+	)
+}
@@ -0,0 +1,38 @@
+package validators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// validateString runs v against value and tells whether it is valid.
+func validateString(t *testing.T, v validator.String, value types.String) bool {
+	t.Helper()
+
+	resp := &validator.StringResponse{}
+	v.ValidateString(context.Background(), validator.StringRequest{Path: path.Root("test"), ConfigValue: value}, resp)
+	return !resp.Diagnostics.HasError()
+}
+
+// testStringValidator checks v accepts null, unknown and valid values, and rejects invalid ones.
+func testStringValidator(t *testing.T, v validator.String, valid []string, invalid []string) {
+	t.Helper()
+
+	if !validateString(t, v, types.StringNull()) || !validateString(t, v, types.StringUnknown()) {
+		t.Error("null and unknown values must be left to other checks")
+	}
+	for _, value := range valid {
+		if !validateString(t, v, types.StringValue(value)) {
+			t.Errorf("expected %q to be valid", value)
+		}
+	}
+	for _, value := range invalid {
+		if validateString(t, v, types.StringValue(value)) {
+			t.Errorf("expected %q to be invalid", value)
+		}
+	}
+}